@@ -0,0 +1,27 @@
+package rebecca
+
+// This file contains thin exported functions related to associations only.
+//
+// For unexported functions see: helpers.go
+//
+// For Context see: context.go
+
+// Load is for fetching associated records of the record, declared with
+// rebecca_belongs_to, rebecca_has_one and rebecca_has_many tags, for example:
+//
+//	type Person struct {
+//		rebecca.ModelMetadata `tablename:"people"`
+//
+//		ID    int    `rebecca:"id" rebecca_primary:"true"`
+//		Posts []Post `rebecca_has_many:"Posts,foreign_key=person_id"`
+//	}
+//
+//	rebecca.Load(&person, "Posts")
+//
+// record is either a pointer to the record or a slice of records, names can be
+// nested, like "Posts.Comments". To fetch associated records together with
+// records themselves, see Context.Preload
+func Load(record interface{}, names ...string) error {
+	ctx := &Context{}
+	return ctx.Load(record, names...)
+}
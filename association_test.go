@@ -0,0 +1,472 @@
+package rebecca
+
+import (
+	"database/sql"
+	"reflect"
+	"testing"
+
+	"github.com/waterlink/rebecca/context"
+	"github.com/waterlink/rebecca/driver/fake"
+	"github.com/waterlink/rebecca/field"
+)
+
+type Writer struct {
+	ModelMetadata `tablename:"writers"`
+
+	ID      int        `rebecca:"id" rebecca_primary:"true"`
+	Name    string     `rebecca:"name"`
+	Books   []Book     `rebecca_has_many:"Books"`
+	Profile *Biography `rebecca_has_one:"foreign_key=author"`
+}
+
+type Book struct {
+	ModelMetadata `tablename:"books"`
+
+	ID       int     `rebecca:"id" rebecca_primary:"true"`
+	Title    string  `rebecca:"title"`
+	WriterID int     `rebecca:"writer_id"`
+	Writer   *Writer `rebecca_belongs_to:"Writer"`
+}
+
+type Biography struct {
+	ModelMetadata `tablename:"biographies"`
+
+	ID       int    `rebecca:"id" rebecca_primary:"true"`
+	Text     string `rebecca:"text"`
+	AuthorID int    `rebecca:"author"`
+}
+
+func TestAssociations(t *testing.T) {
+	SetupDriver(fake.NewDriver())
+
+	john := &Writer{Name: "John"}
+	jane := &Writer{Name: "Jane"}
+	for _, w := range []*Writer{john, jane} {
+		if err := Save(w); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	first := &Book{Title: "First", Writer: john}
+	second := &Book{Title: "Second", Writer: jane}
+	third := &Book{Title: "Third", Writer: john}
+	for _, b := range []*Book{first, second, third} {
+		if err := Save(b); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	bio := &Biography{Text: "Born in 1970"}
+	jane.Profile = bio
+	if err := Save(jane); err != nil {
+		t.Fatal(err)
+	}
+	if err := Save(bio); err != nil {
+		t.Fatal(err)
+	}
+
+	examples := map[string]struct {
+		action   func() (interface{}, error)
+		expected interface{}
+		err      string
+	}{
+		"foreign key of belongs_to is assigned on save": {
+			action: func() (interface{}, error) {
+				return []int{first.WriterID, second.WriterID, third.WriterID}, nil
+			},
+			expected: []int{john.ID, jane.ID, john.ID},
+		},
+
+		"foreign key of has_one is assigned on save of the parent": {
+			action: func() (interface{}, error) {
+				return bio.AuthorID, nil
+			},
+			expected: jane.ID,
+		},
+
+		"Load has_many": {
+			action: func() (interface{}, error) {
+				w := &Writer{}
+				if err := Get(w, john.ID); err != nil {
+					return nil, err
+				}
+				err := Load(w, "Books")
+				return w.Books, err
+			},
+			expected: []Book{
+				{ID: first.ID, Title: "First", WriterID: john.ID},
+				{ID: third.ID, Title: "Third", WriterID: john.ID},
+			},
+		},
+
+		"Load has_many without associated records": {
+			action: func() (interface{}, error) {
+				w := &Writer{ID: 42}
+				err := Load(w, "Books")
+				return w.Books, err
+			},
+			expected: []Book{},
+		},
+
+		"Load has_one with custom foreign key": {
+			action: func() (interface{}, error) {
+				w := &Writer{ID: jane.ID}
+				err := Load(w, "Profile")
+				return w.Profile, err
+			},
+			expected: &Biography{ID: bio.ID, Text: "Born in 1970", AuthorID: jane.ID},
+		},
+
+		"Load belongs_to": {
+			action: func() (interface{}, error) {
+				b := &Book{}
+				if err := Get(b, second.ID); err != nil {
+					return nil, err
+				}
+				err := Load(b, "Writer")
+				return b.Writer, err
+			},
+			expected: &Writer{ID: jane.ID, Name: "Jane"},
+		},
+
+		"Load of slice of records": {
+			action: func() (interface{}, error) {
+				books := []Book{}
+				if err := (&Context{Order: "id"}).All(&books); err != nil {
+					return nil, err
+				}
+				err := Load(&books, "Writer")
+
+				names := []string{}
+				for _, b := range books {
+					names = append(names, b.Writer.Name)
+				}
+				return names, err
+			},
+			expected: []string{"John", "Jane", "John"},
+		},
+
+		"unknown association": {
+			action: func() (interface{}, error) {
+				return nil, Load(&Writer{}, "Comments")
+			},
+			err: "Unable to find association Comments of writers",
+		},
+
+		"missing foreign key": {
+			action: func() (interface{}, error) {
+				type Shelf struct {
+					ModelMetadata `tablename:"shelves"`
+
+					ID    int    `rebecca:"id" rebecca_primary:"true"`
+					Books []Book `rebecca_has_many:"Books"`
+				}
+
+				return nil, Load(&Shelf{ID: 1}, "Books")
+			},
+			err: "Unable to load association Books - Unable to resolve association Books - foreign key shelf_id or ShelfID not found on books",
+		},
+
+		"unknown bare option of belongs_to": {
+			action: func() (interface{}, error) {
+				type Review struct {
+					ModelMetadata `tablename:"reviews"`
+
+					ID       int     `rebecca:"id" rebecca_primary:"true"`
+					WriterID int     `rebecca:"writer_id"`
+					Writer   *Writer `rebecca_belongs_to:"Author"`
+				}
+
+				return nil, Load(&Review{}, "Writer")
+			},
+			err: "Unable to fetch record's metadata - type=github.com/waterlink/rebecca.Review - Unknown option Author of association Writer - it is neither the name of the field nor a column of reviews, use foreign_key=column",
+		},
+
+		"unknown bare option of has_many": {
+			action: func() (interface{}, error) {
+				type Library struct {
+					ModelMetadata `tablename:"libraries"`
+
+					ID    int    `rebecca:"id" rebecca_primary:"true"`
+					Books []Book `rebecca_has_many:"Volumes"`
+				}
+
+				return nil, Load(&Library{ID: 1}, "Books")
+			},
+			err: "Unable to load association Books - Unable to resolve association Books - Unknown option Volumes of association Books - it is neither the name of the field nor a column of books, use foreign_key=column",
+		},
+	}
+
+	for info, e := range examples {
+		t.Log(info)
+		actual, err := e.action()
+		if errRepr(err) != errRepr(nil) || e.err != "" {
+			if errRepr(err) != e.err {
+				t.Errorf("Expected %s to equal %s", errRepr(err), e.err)
+			}
+			continue
+		}
+
+		if !reflect.DeepEqual(actual, e.expected) {
+			t.Errorf("Expected %+v to equal %+v", actual, e.expected)
+		}
+	}
+}
+
+func TestNullableForeignKeys(t *testing.T) {
+	SetupDriver(fake.NewDriver())
+
+	type Draft struct {
+		ModelMetadata `tablename:"drafts"`
+
+		ID       int           `rebecca:"id" rebecca_primary:"true"`
+		Title    string        `rebecca:"title"`
+		WriterID *int          `rebecca:"writer_id"`
+		CriticID sql.NullInt64 `rebecca:"critic_id"`
+		Writer   *Writer       `rebecca_belongs_to:"Writer"`
+	}
+
+	type Critic struct {
+		ModelMetadata `tablename:"critics"`
+
+		ID     int     `rebecca:"id" rebecca_primary:"true"`
+		Name   string  `rebecca:"name"`
+		Drafts []Draft `rebecca_has_many:"critic_id"`
+	}
+
+	john := &Writer{Name: "John"}
+	if err := Save(john); err != nil {
+		t.Fatal(err)
+	}
+
+	first := &Draft{Title: "First", Writer: john}
+	second := &Draft{Title: "Second"}
+	for _, d := range []*Draft{first, second} {
+		if err := Save(d); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if first.WriterID == nil || *first.WriterID != john.ID {
+		t.Errorf("Expected foreign key %v to equal %d", first.WriterID, john.ID)
+	}
+
+	drafts := []Draft{}
+	if err := (&Context{Order: "id"}).All(&drafts); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := Load(&drafts, "Writer"); err != nil {
+		t.Fatal(err)
+	}
+
+	expectedWriters := []*Writer{{ID: john.ID, Name: "John"}, nil}
+	actualWriters := []*Writer{drafts[0].Writer, drafts[1].Writer}
+	if !reflect.DeepEqual(actualWriters, expectedWriters) {
+		t.Errorf("Expected %+v to equal %+v", actualWriters, expectedWriters)
+	}
+
+	critic := &Critic{Name: "Jane", Drafts: []Draft{*second}}
+	if err := Save(critic); err != nil {
+		t.Fatal(err)
+	}
+
+	expectedKey := sql.NullInt64{Int64: int64(critic.ID), Valid: true}
+	if critic.Drafts[0].CriticID != expectedKey {
+		t.Errorf("Expected foreign key %+v to equal %+v", critic.Drafts[0].CriticID, expectedKey)
+	}
+
+	if err := Save(&critic.Drafts[0]); err != nil {
+		t.Fatal(err)
+	}
+
+	loaded := &Critic{ID: critic.ID}
+	if err := Load(loaded, "Drafts"); err != nil {
+		t.Fatal(err)
+	}
+
+	expectedDrafts := []Draft{{ID: second.ID, Title: "Second", CriticID: expectedKey}}
+	if !reflect.DeepEqual(loaded.Drafts, expectedDrafts) {
+		t.Errorf("Expected %+v to equal %+v", loaded.Drafts, expectedDrafts)
+	}
+}
+
+// queryCountingDriver is for recording fetching queries, each one as the
+// tablename followed by its structured ordering
+type queryCountingDriver struct {
+	*fake.Driver
+
+	queries []string
+}
+
+func (d *queryCountingDriver) All(tablename string, fields []field.Field, ctx context.Context) ([][]field.Field, error) {
+	d.record(tablename, ctx)
+	return d.Driver.All(tablename, fields, ctx)
+}
+
+func (d *queryCountingDriver) Where(tablename string, fields []field.Field, ctx context.Context, where string, args ...interface{}) ([][]field.Field, error) {
+	d.record(tablename, ctx)
+	return d.Driver.Where(tablename, fields, ctx, where, args...)
+}
+
+func (d *queryCountingDriver) First(tablename string, fields []field.Field, ctx context.Context, where string, args ...interface{}) ([]field.Field, error) {
+	d.record(tablename, ctx)
+	return d.Driver.First(tablename, fields, ctx, where, args...)
+}
+
+func (d *queryCountingDriver) Get(tx interface{}, tablename string, fields []field.Field, ID field.Field) ([]field.Field, error) {
+	d.queries = append(d.queries, tablename)
+	return d.Driver.Get(tx, tablename, fields, ID)
+}
+
+func (d *queryCountingDriver) record(tablename string, ctx context.Context) {
+	query := tablename
+	for _, o := range ctx.GetOrderBy() {
+		query = query + " ORDER BY " + o.Column
+	}
+	d.queries = append(d.queries, query)
+}
+
+func TestPreload(t *testing.T) {
+	d := &queryCountingDriver{Driver: fake.NewDriver()}
+	SetupDriver(d)
+
+	type Note struct {
+		ModelMetadata `tablename:"notes"`
+
+		ID      int    `rebecca:"id" rebecca_primary:"true"`
+		Text    string `rebecca:"text"`
+		EntryID int    `rebecca:"entry_id"`
+	}
+
+	type Entry struct {
+		ModelMetadata `tablename:"entries"`
+
+		ID      int     `rebecca:"id" rebecca_primary:"true"`
+		Title   string  `rebecca:"title"`
+		OwnerID int     `rebecca:"owner_id"`
+		Notes   []*Note `rebecca_has_many:"entry_id"`
+	}
+
+	type Owner struct {
+		ModelMetadata `tablename:"owners"`
+
+		ID      int     `rebecca:"id" rebecca_primary:"true"`
+		Name    string  `rebecca:"name"`
+		Entries []Entry `rebecca_has_many:"owner_id"`
+	}
+
+	john := &Owner{Name: "John"}
+	jane := &Owner{Name: "Jane"}
+	bob := &Owner{Name: "Bob"}
+	for _, o := range []*Owner{john, jane, bob} {
+		if err := Save(o); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	hello := &Entry{Title: "Hello", OwnerID: john.ID}
+	world := &Entry{Title: "World", OwnerID: jane.ID}
+	again := &Entry{Title: "Again", OwnerID: john.ID}
+	for _, e := range []*Entry{hello, world, again} {
+		if err := Save(e); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	first := &Note{Text: "First", EntryID: hello.ID}
+	second := &Note{Text: "Second", EntryID: again.ID}
+	third := &Note{Text: "Third", EntryID: hello.ID}
+	for _, n := range []*Note{first, second, third} {
+		if err := Save(n); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	examples := map[string]struct {
+		action   func() (interface{}, error)
+		expected interface{}
+		queries  []string
+		err      string
+	}{
+		"All with nested preload": {
+			action: func() (interface{}, error) {
+				owners := []Owner{}
+				err := (&Context{Order: "id"}).Preload("Entries", "Entries.Notes").All(&owners)
+				return owners, err
+			},
+			expected: []Owner{
+				{ID: john.ID, Name: "John", Entries: []Entry{
+					{ID: hello.ID, Title: "Hello", OwnerID: john.ID, Notes: []*Note{first, third}},
+					{ID: again.ID, Title: "Again", OwnerID: john.ID, Notes: []*Note{second}},
+				}},
+				{ID: jane.ID, Name: "Jane", Entries: []Entry{
+					{ID: world.ID, Title: "World", OwnerID: jane.ID, Notes: []*Note{}},
+				}},
+				{ID: bob.ID, Name: "Bob", Entries: []Entry{}},
+			},
+			queries: []string{"owners", "entries ORDER BY id", "notes ORDER BY id"},
+		},
+
+		"Where with preload of nested association only": {
+			action: func() (interface{}, error) {
+				owners := []*Owner{}
+				err := (&Context{}).Preload("Entries.Notes").Where(&owners, Eq("name", "Jane"))
+				return owners, err
+			},
+			expected: []*Owner{
+				{ID: jane.ID, Name: "Jane", Entries: []Entry{
+					{ID: world.ID, Title: "World", OwnerID: jane.ID, Notes: []*Note{}},
+				}},
+			},
+		},
+
+		"First with preload": {
+			action: func() (interface{}, error) {
+				entry := &Entry{}
+				err := (&Context{}).Preload("Notes").First(entry, Eq("title", "Again"))
+				return entry, err
+			},
+			expected: &Entry{ID: again.ID, Title: "Again", OwnerID: john.ID, Notes: []*Note{second}},
+		},
+
+		"Get with preload": {
+			action: func() (interface{}, error) {
+				entry := &Entry{}
+				err := (&Context{}).Preload("Notes").Get(entry, hello.ID)
+				return entry, err
+			},
+			expected: &Entry{ID: hello.ID, Title: "Hello", OwnerID: john.ID, Notes: []*Note{first, third}},
+			queries:  []string{"entries", "notes ORDER BY id"},
+		},
+
+		"Preload of unknown association": {
+			action: func() (interface{}, error) {
+				owners := []Owner{}
+				err := (&Context{}).Preload("Entries.Tags").All(&owners)
+				return owners, err
+			},
+			err: "Unable to find association Tags of entries",
+		},
+	}
+
+	for info, e := range examples {
+		t.Log(info)
+		d.queries = nil
+		actual, err := e.action()
+		if errRepr(err) != errRepr(nil) || e.err != "" {
+			if errRepr(err) != e.err {
+				t.Errorf("Expected %s to equal %s", errRepr(err), e.err)
+			}
+			continue
+		}
+
+		if !reflect.DeepEqual(actual, e.expected) {
+			t.Errorf("Expected %+v to equal %+v", actual, e.expected)
+		}
+
+		if e.queries != nil && !reflect.DeepEqual(d.queries, e.queries) {
+			t.Errorf("Expected queries %+v to equal %+v", d.queries, e.queries)
+		}
+	}
+}
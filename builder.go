@@ -0,0 +1,212 @@
+package rebecca
+
+// This file contains thin exported functions and types related to chainable
+// query builder only.
+//
+// For unexported functions see: helpers.go
+//
+// For Context see: context.go
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/waterlink/rebecca/condition"
+	"github.com/waterlink/rebecca/context"
+	"github.com/waterlink/rebecca/driver"
+)
+
+// Builder is for composing queries step by step, for example, across
+// multiple layers of the application. It is immutable, each method creates
+// new Builder, so partially built queries can be shared. Use Q to start
+type Builder struct {
+	ctx   *Context
+	where []string
+	args  []interface{}
+	err   error
+}
+
+// Q is for starting new query, for example:
+//
+//	rebecca.Q().Where("age > $1", 18).Where(rebecca.Like("name", "J%")).Order("age DESC").Limit(10).All(&people)
+func Q() *Builder {
+	return &Builder{ctx: &Context{}}
+}
+
+// Context is for fetching Context of the query without its where queries
+func (b *Builder) Context() *Context {
+	return b.ctx
+}
+
+// Where is for filtering records, where is either a query with placeholders
+// for args or a structured condition. Multiple Where are combined with AND,
+// placeholders of queries are renumbered, so that each query can start with
+// $1 or use ? and named parameters
+func (b *Builder) Where(where interface{}, args ...interface{}) *Builder {
+	builder := *b
+	if b.err != nil {
+		return &builder
+	}
+
+	switch where := where.(type) {
+	case string:
+		query, args, err := prepareQuery(where, args, driver.Dollar)
+		if err != nil {
+			builder.err = err
+			return &builder
+		}
+
+		if query == "" {
+			return &builder
+		}
+
+		builder.where = append(append([]string{}, b.where...), driver.ShiftPlaceholders(query, len(b.args)))
+		builder.args = append(append([]interface{}{}, b.args...), args...)
+
+	case condition.Condition:
+		if len(args) > 0 {
+			builder.err = fmt.Errorf("Structured condition does not accept arguments, but got: %+v", args)
+			return &builder
+		}
+
+		builder.ctx = b.ctx.SetCondition(andConditions(b.ctx.condition, where)).(*Context)
+
+	default:
+		builder.err = fmt.Errorf("Where query is required to be either string or structured condition, but got: %T", where)
+	}
+
+	return &builder
+}
+
+// Scope is for applying scopes defined for the model, see Context.Scope
+func (b *Builder) Scope(names ...string) *Builder {
+	builder := *b
+	builder.ctx = b.ctx.Scope(names...)
+	return &builder
+}
+
+// Unscoped is for disabling default scopes of the model, see
+// Context.Unscoped
+func (b *Builder) Unscoped() *Builder {
+	builder := *b
+	builder.ctx = b.ctx.Unscoped()
+	return &builder
+}
+
+// Preload is for fetching associated records after the query, see
+// Context.Preload
+func (b *Builder) Preload(names ...string) *Builder {
+	builder := *b
+	builder.ctx = b.ctx.Preload(names...)
+	return &builder
+}
+
+// Order is for ordering records, see Context.Order
+func (b *Builder) Order(order string) *Builder {
+	return b.with(b.ctx.SetOrder(order))
+}
+
+// OrderBy is for ordering records by validated columns, see Context.OrderBy
+func (b *Builder) OrderBy(orderings ...context.Ordering) *Builder {
+	return b.with(b.ctx.SetOrderBy(orderings))
+}
+
+// Group is for grouping records, see Context.Group
+func (b *Builder) Group(group string) *Builder {
+	return b.with(b.ctx.SetGroup(group))
+}
+
+// Having is for filtering groups of records, see Context.Having
+func (b *Builder) Having(having string, args ...interface{}) *Builder {
+	return b.with(b.ctx.SetHaving(having, args...))
+}
+
+// Joins is for joining other tables, see Context.Joins
+func (b *Builder) Joins(joins ...string) *Builder {
+	return b.with(b.ctx.SetJoins(append(append([]string{}, b.ctx.Joins...), joins...)))
+}
+
+// Distinct is for fetching only distinct records, see Context.Distinct
+func (b *Builder) Distinct() *Builder {
+	return b.with(b.ctx.SetDistinct(true))
+}
+
+// Lock is for locking fetched records, see Context.Lock
+func (b *Builder) Lock(lock context.Lock) *Builder {
+	return b.with(b.ctx.SetLock(lock))
+}
+
+// Limit is for limiting amount of records
+func (b *Builder) Limit(limit int) *Builder {
+	return b.with(b.ctx.SetLimit(limit))
+}
+
+// Skip is for skipping first records
+func (b *Builder) Skip(skip int) *Builder {
+	return b.with(b.ctx.SetSkip(skip))
+}
+
+// Tx is for running the query within transaction
+func (b *Builder) Tx(tx *Transaction) *Builder {
+	return b.with(b.ctx.SetTx(tx.tx))
+}
+
+// All is for fetching all records matching the query
+func (b *Builder) All(records interface{}) error {
+	if b.err != nil {
+		return b.err
+	}
+	return b.ctx.Where(records, b.query(), b.args...)
+}
+
+// First is for fetching only first record matching the query
+func (b *Builder) First(record interface{}) error {
+	if b.err != nil {
+		return b.err
+	}
+	return b.ctx.First(record, b.query(), b.args...)
+}
+
+// Count is for counting records matching the query
+func (b *Builder) Count(record interface{}) (int, error) {
+	if b.err != nil {
+		return 0, b.err
+	}
+	return b.ctx.Count(record, b.query(), b.args...)
+}
+
+// Iterate is for fetching records matching the query lazily one by one
+func (b *Builder) Iterate(record interface{}) (*Iterator, error) {
+	if b.err != nil {
+		return nil, b.err
+	}
+	return b.ctx.Iterate(record, b.query(), b.args...)
+}
+
+// Paginate is for fetching given page of records matching the query
+// together with the total count of them, see Context.Paginate
+func (b *Builder) Paginate(records interface{}, page, perPage int) (*Pagination, error) {
+	if b.err != nil {
+		return nil, b.err
+	}
+	return b.ctx.Paginate(records, page, perPage, b.query(), b.args...)
+}
+
+func (b *Builder) with(ctx context.Context) *Builder {
+	builder := *b
+	builder.ctx = ctx.(*Context)
+	return &builder
+}
+
+// query combines where queries with AND
+func (b *Builder) query() string {
+	if len(b.where) == 1 {
+		return b.where[0]
+	}
+
+	parts := []string{}
+	for _, where := range b.where {
+		parts = append(parts, "("+where+")")
+	}
+	return strings.Join(parts, " AND ")
+}
@@ -0,0 +1,141 @@
+package rebecca
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/waterlink/rebecca/driver/fake"
+	"github.com/waterlink/rebecca/field"
+)
+
+func TestBuilder(t *testing.T) {
+	examples := map[string]struct {
+		builder *Builder
+		query   string
+		args    []interface{}
+		err     string
+	}{
+		"single where": {
+			builder: Q().Where("age > $1", 18),
+			query:   "age > $1",
+			args:    []interface{}{18},
+		},
+
+		"multiple wheres": {
+			builder: Q().Where("age > $1", 18).Where("name = $1 OR nickname = $1", "John").Where("level < ?", 3),
+			query:   "(age > $1) AND (name = $2 OR nickname = $2) AND (level < $3)",
+			args:    []interface{}{18, "John", 3},
+		},
+
+		"named parameters": {
+			builder: Q().Where("age > $1", 18).Where("name = :name", Named{"name": "John"}),
+			query:   "(age > $1) AND (name = $2)",
+			args:    []interface{}{18, "John"},
+		},
+
+		"empty where": {
+			builder: Q().Where("").Where("age > $1", 18),
+			query:   "age > $1",
+			args:    []interface{}{18},
+		},
+
+		"condition with arguments": {
+			builder: Q().Where(Eq("age", 18), 21).Where("name = $1", "John"),
+			err:     "Structured condition does not accept arguments, but got: [21]",
+		},
+
+		"unknown where": {
+			builder: Q().Where(42),
+			err:     "Where query is required to be either string or structured condition, but got: int",
+		},
+	}
+
+	for info, e := range examples {
+		t.Log(info)
+		if errRepr(e.builder.err) != errRepr(nil) || e.err != "" {
+			if errRepr(e.builder.err) != e.err {
+				t.Errorf("Expected %s to equal %s", errRepr(e.builder.err), e.err)
+			}
+			continue
+		}
+
+		if actual := e.builder.query(); actual != e.query {
+			t.Errorf("Expected %s to equal %s", actual, e.query)
+		}
+
+		if !reflect.DeepEqual(e.builder.args, e.args) {
+			t.Errorf("Expected %+v to equal %+v", e.builder.args, e.args)
+		}
+	}
+}
+
+func TestBuilderQueries(t *testing.T) {
+	d := fake.NewDriver()
+	SetupDriver(d)
+
+	type Person struct {
+		ModelMetadata `tablename:"people"`
+
+		ID   int    `rebecca:"id" rebecca_primary:"true"`
+		Name string `rebecca:"name"`
+		Age  int    `rebecca:"age"`
+	}
+
+	d.RegisterWhere("(age > $1) AND (name <> $2)", func(record []field.Field, args ...interface{}) (bool, error) {
+		age := record[2].Value.(int)
+		name := record[1].Value.(string)
+		return age > args[0].(int) && name != args[1].(string), nil
+	})
+
+	john := &Person{Name: "John", Age: 34}
+	jane := &Person{Name: "Jane", Age: 17}
+	bob := &Person{Name: "Bob", Age: 45}
+	alice := &Person{Name: "Alice", Age: 29}
+	for _, p := range []*Person{john, jane, bob, alice} {
+		if err := Save(p); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	base := Q().Where("age > $1", 18).Where(Lt("age", 40))
+	filtered := base.Where("name <> $1", "Alice")
+
+	people := []Person{}
+	if err := filtered.Order("age DESC").All(&people); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(people, []Person{*john}) {
+		t.Errorf("Expected %+v to equal %+v", people, []Person{*john})
+	}
+
+	count, err := Q().Where(Gt("age", 18)).Count(&Person{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if count != 3 {
+		t.Errorf("Expected %d to equal 3", count)
+	}
+
+	first := &Person{}
+	if err := Q().Where(Gt("age", 18)).OrderBy(Desc("age")).Skip(1).Limit(1).First(first); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(first, john) {
+		t.Errorf("Expected %+v to equal %+v", first, john)
+	}
+
+	tx, err := Begin()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer tx.Rollback()
+
+	builder := Q().Tx(tx)
+	if builder.Context().GetTx() != tx.tx {
+		t.Errorf("Expected builder to run within transaction")
+	}
+
+	if base.Context().GetTx() != nil {
+		t.Errorf("Expected builder to be immutable")
+	}
+}
@@ -0,0 +1,83 @@
+package rebecca
+
+// This file contains thin exported functions related to structured
+// conditions only.
+//
+// For unexported functions see: helpers.go
+
+import (
+	"reflect"
+
+	"github.com/waterlink/rebecca/condition"
+)
+
+// Eq is for checking that column is equal to value
+func Eq(column string, value interface{}) condition.Condition {
+	return condition.Compare{Column: column, Operator: condition.Eq, Value: value}
+}
+
+// NotEq is for checking that column is not equal to value
+func NotEq(column string, value interface{}) condition.Condition {
+	return condition.Compare{Column: column, Operator: condition.NotEq, Value: value}
+}
+
+// Lt is for checking that column is less than value
+func Lt(column string, value interface{}) condition.Condition {
+	return condition.Compare{Column: column, Operator: condition.Lt, Value: value}
+}
+
+// Lte is for checking that column is less than or equal to value
+func Lte(column string, value interface{}) condition.Condition {
+	return condition.Compare{Column: column, Operator: condition.Lte, Value: value}
+}
+
+// Gt is for checking that column is greater than value
+func Gt(column string, value interface{}) condition.Condition {
+	return condition.Compare{Column: column, Operator: condition.Gt, Value: value}
+}
+
+// Gte is for checking that column is greater than or equal to value
+func Gte(column string, value interface{}) condition.Condition {
+	return condition.Compare{Column: column, Operator: condition.Gte, Value: value}
+}
+
+// In is for checking that column is equal to one of values. Single slice
+// value is expanded, so both In("age", 1, 2) and In("age", []int{1, 2}) work
+func In(column string, values ...interface{}) condition.Condition {
+	if len(values) == 1 {
+		v := reflect.ValueOf(values[0])
+		if v.Kind() == reflect.Slice && v.Type().Elem().Kind() != reflect.Uint8 {
+			values = []interface{}{}
+			for i := 0; i < v.Len(); i++ {
+				values = append(values, v.Index(i).Interface())
+			}
+		}
+	}
+
+	return condition.In{Column: column, Values: values}
+}
+
+// Like is for matching column against SQL LIKE pattern
+func Like(column string, pattern string) condition.Condition {
+	return condition.Like{Column: column, Pattern: pattern}
+}
+
+// IsNull is for checking that column is NULL
+func IsNull(column string) condition.Condition {
+	return condition.IsNull{Column: column}
+}
+
+// And is for checking that all conditions are satisfied
+func And(conditions ...condition.Condition) condition.Condition {
+	return condition.And(conditions)
+}
+
+// Or is for checking that at least one of conditions is satisfied
+func Or(conditions ...condition.Condition) condition.Condition {
+	return condition.Or(conditions)
+}
+
+// Not is for negating the condition
+func Not(cond condition.Condition) condition.Condition {
+	return condition.Not{Condition: cond}
+}
@@ -0,0 +1,68 @@
+// Package condition is for representing structured, driver-independent query
+// conditions. Conditions are constructed with rebecca.Eq, rebecca.In,
+// rebecca.And and friends, and are rendered by each driver in its own way.
+package condition
+
+// Condition is for representing structured query condition
+type Condition interface {
+	isCondition()
+}
+
+// Operator is for representing comparison operator of Compare condition
+type Operator string
+
+// Available comparison operators
+const (
+	Eq    Operator = "="
+	NotEq Operator = "<>"
+	Lt    Operator = "<"
+	Lte   Operator = "<="
+	Gt    Operator = ">"
+	Gte   Operator = ">="
+)
+
+// Compare is for comparing column with value using Operator
+type Compare struct {
+	Column   string
+	Operator Operator
+	Value    interface{}
+}
+
+// In is for checking that column is equal to one of the values
+type In struct {
+	Column string
+	Values []interface{}
+}
+
+// Like is for matching column against SQL LIKE pattern, where % matches any
+// sequence of characters and _ matches any single character
+type Like struct {
+	Column  string
+	Pattern string
+}
+
+// IsNull is for checking that column is NULL
+type IsNull struct {
+	Column string
+}
+
+// And is for checking that all conditions are satisfied. Empty And is always
+// satisfied
+type And []Condition
+
+// Or is for checking that at least one of conditions is satisfied. Empty Or
+// is never satisfied
+type Or []Condition
+
+// Not is for negating the condition
+type Not struct {
+	Condition Condition
+}
+
+func (Compare) isCondition() {}
+func (In) isCondition()      {}
+func (Like) isCondition()    {}
+func (IsNull) isCondition()  {}
+func (And) isCondition()     {}
+func (Or) isCondition()      {}
+func (Not) isCondition()     {}
@@ -0,0 +1,262 @@
+package rebecca
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/waterlink/rebecca/condition"
+	"github.com/waterlink/rebecca/driver/fake"
+)
+
+func TestWhereConditions(t *testing.T) {
+	SetupDriver(fake.NewDriver())
+
+	type Person struct {
+		ModelMetadata `tablename:"people"`
+
+		ID       int     `rebecca:"id" rebecca_primary:"true"`
+		Name     string  `rebecca:"name"`
+		Age      int     `rebecca:"age"`
+		Nickname *string `rebecca:"nickname"`
+	}
+
+	jo := "Jo"
+	p1 := &Person{Name: "John", Age: 9, Nickname: &jo}
+	p2 := &Person{Name: "Sarah", Age: 27}
+	p3 := &Person{Name: "James", Age: 11}
+	p4 := &Person{Name: "Monika", Age: 12}
+	people := []*Person{p1, p2, p3, p4}
+
+	for _, p := range people {
+		if err := Save(p); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	examples := map[string]struct {
+		cond     condition.Condition
+		expected []Person
+	}{
+		"Eq": {
+			cond:     Eq("age", 12),
+			expected: []Person{*p4},
+		},
+
+		"NotEq": {
+			cond:     NotEq("name", "John"),
+			expected: []Person{*p2, *p3, *p4},
+		},
+
+		"Lt": {
+			cond:     Lt("age", 12),
+			expected: []Person{*p1, *p3},
+		},
+
+		"Lte": {
+			cond:     Lte("age", 12),
+			expected: []Person{*p1, *p3, *p4},
+		},
+
+		"Gt": {
+			cond:     Gt("age", 12),
+			expected: []Person{*p2},
+		},
+
+		"Gte": {
+			cond:     Gte("age", 12),
+			expected: []Person{*p2, *p4},
+		},
+
+		"In": {
+			cond:     In("age", 9, 27),
+			expected: []Person{*p1, *p2},
+		},
+
+		"In with slice": {
+			cond:     In("name", []string{"James", "Monika"}),
+			expected: []Person{*p3, *p4},
+		},
+
+		"Like": {
+			cond:     Like("name", "J%n%"),
+			expected: []Person{*p1},
+		},
+
+		"IsNull": {
+			cond:     IsNull("nickname"),
+			expected: []Person{*p2, *p3, *p4},
+		},
+
+		"And": {
+			cond:     And(Gt("age", 10), Like("name", "%a%")),
+			expected: []Person{*p2, *p3, *p4},
+		},
+
+		"Or": {
+			cond:     Or(Lt("age", 10), Eq("name", "Sarah")),
+			expected: []Person{*p1, *p2},
+		},
+
+		"Not": {
+			cond:     Not(Or(Lt("age", 10), Eq("name", "Sarah"))),
+			expected: []Person{*p3, *p4},
+		},
+
+		"by field name": {
+			cond:     Eq("Name", "Sarah"),
+			expected: []Person{*p2},
+		},
+	}
+
+	for info, e := range examples {
+		t.Log(info)
+		actual := []Person{}
+		if err := Where(&actual, e.cond); err != nil {
+			t.Fatal(err)
+		}
+
+		if !reflect.DeepEqual(actual, e.expected) {
+			t.Errorf("Expected %+v to equal to %+v", actual, e.expected)
+		}
+	}
+
+	actualOne := &Person{}
+	if err := First(actualOne, And(Gt("age", 10), Lt("age", 20))); err != nil {
+		t.Fatal(err)
+	}
+
+	if !reflect.DeepEqual(actualOne, p3) {
+		t.Errorf("Expected %+v to equal to %+v", actualOne, p3)
+	}
+
+	count, err := Count(&Person{}, Gt("age", 10))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if count != 3 {
+		t.Errorf("Expected %d to equal to %d", count, 3)
+	}
+
+	err = Where(&[]Person{}, Eq("age", 12), 12)
+	expectedErr := "Structured condition does not accept arguments, but got: [12]"
+	if errRepr(err) != expectedErr {
+		t.Errorf("Expected %s to equal %s", errRepr(err), expectedErr)
+	}
+
+	err = Where(&[]Person{}, 42)
+	expectedErr = "Where query is required to be either string or structured condition, but got: int"
+	if errRepr(err) != expectedErr {
+		t.Errorf("Expected %s to equal %s", errRepr(err), expectedErr)
+	}
+
+	err = Where(&[]Person{}, Or(Eq("name", "John"), Eq("1=1; DROP TABLE people; --", 1)))
+	expectedErr = "Unable to use unknown column 1=1; DROP TABLE people; -- in condition"
+	if errRepr(err) != expectedErr {
+		t.Errorf("Expected %s to equal %s", errRepr(err), expectedErr)
+	}
+
+	err = Q().Where(Lt("lower(name)", "j")).All(&[]Person{})
+	expectedErr = "Unable to use unknown column lower(name) in condition"
+	if errRepr(err) != expectedErr {
+		t.Errorf("Expected %s to equal %s", errRepr(err), expectedErr)
+	}
+
+	err = Where(&[]Person{}, condition.Compare{Column: "age", Operator: "= 1 OR 1 =", Value: 1})
+	expectedErr = "Unable to compare column age with unknown operator = 1 OR 1 ="
+	if errRepr(err) != expectedErr {
+		t.Errorf("Expected %s to equal %s", errRepr(err), expectedErr)
+	}
+}
+
+func TestWhereExample(t *testing.T) {
+	SetupDriver(fake.NewDriver())
+
+	type Person struct {
+		ModelMetadata `tablename:"people"`
+
+		ID       int     `rebecca:"id" rebecca_primary:"true"`
+		Name     string  `rebecca:"name"`
+		Age      int     `rebecca:"age"`
+		Nickname *string `rebecca:"nickname"`
+	}
+
+	johnny := "Johnny"
+	p1 := &Person{Name: "John", Age: 9}
+	p2 := &Person{Name: "Sarah", Age: 27}
+	p3 := &Person{Name: "John", Age: 0}
+	p4 := &Person{Name: "John", Age: 27, Nickname: &johnny}
+	people := []*Person{p1, p2, p3, p4}
+
+	for _, p := range people {
+		if err := Save(p); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	examples := map[string]struct {
+		example  *Person
+		include  []string
+		expected []Person
+	}{
+		"with one field": {
+			example:  &Person{Name: "John"},
+			expected: []Person{*p1, *p3, *p4},
+		},
+
+		"with multiple fields": {
+			example:  &Person{Name: "John", Age: 27},
+			expected: []Person{*p4},
+		},
+
+		"with included zero field": {
+			example:  &Person{Name: "John"},
+			include:  []string{"Age"},
+			expected: []Person{*p3},
+		},
+
+		"with included zero field by driver name": {
+			example:  &Person{Name: "John"},
+			include:  []string{"age"},
+			expected: []Person{*p3},
+		},
+
+		"with included nil field": {
+			example:  &Person{Name: "John"},
+			include:  []string{"Nickname"},
+			expected: []Person{*p1, *p3},
+		},
+
+		"with empty example": {
+			example:  &Person{},
+			expected: []Person{*p1, *p2, *p3, *p4},
+		},
+	}
+
+	for info, e := range examples {
+		t.Log(info)
+		actual := []Person{}
+		if err := WhereExample(&actual, e.example, e.include...); err != nil {
+			t.Fatal(err)
+		}
+
+		if !reflect.DeepEqual(actual, e.expected) {
+			t.Errorf("Expected %+v to equal to %+v", actual, e.expected)
+		}
+	}
+
+	actualOne := &Person{}
+	if err := FirstExample(actualOne, &Person{Age: 27}); err != nil {
+		t.Fatal(err)
+	}
+
+	if !reflect.DeepEqual(actualOne, p2) {
+		t.Errorf("Expected %+v to equal to %+v", actualOne, p2)
+	}
+
+	err := WhereExample(&[]Person{}, &Person{}, "Height")
+	expectedErr := "Unable to include unknown field Height of example &{ModelMetadata:{} ID:0 Name: Age:0 Nickname:<nil>}"
+	if errRepr(err) != expectedErr {
+		t.Errorf("Expected %s to equal %s", errRepr(err), expectedErr)
+	}
+}
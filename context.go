@@ -5,8 +5,11 @@ package rebecca
 // For unexported functions see: helpers.go
 
 import (
+	stdcontext "context"
 	"fmt"
+	"reflect"
 
+	"github.com/waterlink/rebecca/condition"
 	"github.com/waterlink/rebecca/context"
 	"github.com/waterlink/rebecca/driver"
 )
@@ -19,6 +22,30 @@ type Context struct {
 	// Defines grouping criteria of the query
 	Group string
 
+	// Defines joins of the query, each one is either raw join clause or name
+	// of the join defined for the model with DefineJoin
+	Joins []string
+
+	// Defines filtering of groups of the query, with placeholders for
+	// HavingArgs
+	Having     string
+	HavingArgs []interface{}
+
+	// Defines if only distinct records are requested
+	Distinct bool
+
+	// Defines expressions, only first record for each distinct value of which
+	// is requested (DISTINCT ON of postgres)
+	DistinctOn []string
+
+	// Defines how fetched records are locked until the end of transaction,
+	// for example: rebecca.ForUpdate.NoWait(). Available only in transactions
+	Lock context.Lock
+
+	// Defines which fields are fetched, by field name or by driver name. All
+	// fields are fetched when empty, the rest are left untouched otherwise
+	Select []string
+
 	// Defines maximum amount of records requested for the query
 	Limit int
 
@@ -26,7 +53,16 @@ type Context struct {
 	Skip   int
 	Offset int // alias of Skip
 
-	tx interface{}
+	tx        interface{}
+	orderBy   []context.Ordering
+	cursor    context.Cursor
+	condition condition.Condition
+	scopes    []string
+	unscoped  bool
+	tenant    interface{}
+	preloads  []string
+	after     string
+	before    string
 }
 
 // GetOrder is for fetching context's Order. Used by drivers
@@ -34,6 +70,12 @@ func (c *Context) GetOrder() string {
 	return c.Order
 }
 
+// GetOrderBy is for fetching context's structured ordering, see OrderBy.
+// Used by drivers
+func (c *Context) GetOrderBy() []context.Ordering {
+	return c.orderBy
+}
+
 // GetGroup is for fetching context's Group. Used by drivers
 func (c *Context) GetGroup() string {
 	return c.Group
@@ -58,6 +100,47 @@ func (c *Context) GetTx() interface{} {
 	return c.tx
 }
 
+// GetCursor is for fetching context's keyset pagination Cursor. Used by drivers
+func (c *Context) GetCursor() context.Cursor {
+	return c.cursor
+}
+
+// GetCondition is for fetching context's structured condition, that is
+// required to be satisfied in addition to where query. Used by drivers
+func (c *Context) GetCondition() condition.Condition {
+	return c.condition
+}
+
+// GetJoins is for fetching context's Joins. Used by drivers
+func (c *Context) GetJoins() []string {
+	return c.Joins
+}
+
+// GetHaving is for fetching context's Having. Used by drivers
+func (c *Context) GetHaving() string {
+	return c.Having
+}
+
+// GetHavingArgs is for fetching context's HavingArgs. Used by drivers
+func (c *Context) GetHavingArgs() []interface{} {
+	return c.HavingArgs
+}
+
+// GetDistinct is for fetching context's Distinct. Used by drivers
+func (c *Context) GetDistinct() bool {
+	return c.Distinct
+}
+
+// GetDistinctOn is for fetching context's DistinctOn. Used by drivers
+func (c *Context) GetDistinctOn() []string {
+	return c.DistinctOn
+}
+
+// GetLock is for fetching context's Lock. Used by drivers
+func (c *Context) GetLock() context.Lock {
+	return c.Lock
+}
+
 // SetOrder is for setting context's Order, it creates new Context. Used by drivers
 func (c *Context) SetOrder(order string) context.Context {
 	ctx := c.makeCopy()
@@ -65,6 +148,13 @@ func (c *Context) SetOrder(order string) context.Context {
 	return &ctx
 }
 
+// SetOrderBy is for setting context's structured ordering. Used by drivers
+func (c *Context) SetOrderBy(orderBy []context.Ordering) context.Context {
+	ctx := c.makeCopy()
+	ctx.orderBy = orderBy
+	return &ctx
+}
+
 // SetGroup is for setting context's Group. Used by drivers
 func (c *Context) SetGroup(group string) context.Context {
 	ctx := c.makeCopy()
@@ -94,61 +184,262 @@ func (c *Context) SetTx(tx interface{}) context.Context {
 	return &ctx
 }
 
-// All is for fetching all records
+// SetCursor is for setting context's keyset pagination Cursor. Used by drivers
+func (c *Context) SetCursor(cursor context.Cursor) context.Context {
+	ctx := c.makeCopy()
+	ctx.cursor = cursor
+	return &ctx
+}
+
+// SetCondition is for setting context's structured condition. Used by drivers
+func (c *Context) SetCondition(cond condition.Condition) context.Context {
+	ctx := c.makeCopy()
+	ctx.condition = cond
+	return &ctx
+}
+
+// SetJoins is for setting context's Joins. Used by drivers
+func (c *Context) SetJoins(joins []string) context.Context {
+	ctx := c.makeCopy()
+	ctx.Joins = joins
+	return &ctx
+}
+
+// SetHaving is for setting context's Having and HavingArgs. Used by drivers
+func (c *Context) SetHaving(having string, args ...interface{}) context.Context {
+	ctx := c.makeCopy()
+	ctx.Having = having
+	ctx.HavingArgs = args
+	return &ctx
+}
+
+// SetDistinct is for setting context's Distinct. Used by drivers
+func (c *Context) SetDistinct(distinct bool) context.Context {
+	ctx := c.makeCopy()
+	ctx.Distinct = distinct
+	return &ctx
+}
+
+// SetDistinctOn is for setting context's DistinctOn. Used by drivers
+func (c *Context) SetDistinctOn(distinctOn []string) context.Context {
+	ctx := c.makeCopy()
+	ctx.DistinctOn = distinctOn
+	return &ctx
+}
+
+// SetLock is for setting context's Lock. Used by drivers
+func (c *Context) SetLock(lock context.Lock) context.Context {
+	ctx := c.makeCopy()
+	ctx.Lock = lock
+	return &ctx
+}
+
+// OrderBy is for creating the same Context ordered by given columns, for
+// example: ctx.OrderBy(rebecca.Asc("age"), rebecca.Desc("name").NullsLast()).
+// Unlike Order, columns are validated against fields of the model, hence it
+// is safe to order by columns chosen by clients. It can not be combined with
+// Order
+func (c *Context) OrderBy(orderings ...context.Ordering) *Context {
+	ctx := c.makeCopy()
+	ctx.orderBy = orderings
+	return &ctx
+}
+
+// Scope is for creating the same Context with scopes defined for the model
+// with DefineScope applied, they are combined with AND
+func (c *Context) Scope(names ...string) *Context {
+	ctx := c.makeCopy()
+	ctx.scopes = append(append([]string{}, c.scopes...), names...)
+	return &ctx
+}
+
+// Unscoped is for creating the same Context without default scopes of the
+// model, see DefineDefaultScope
+func (c *Context) Unscoped() *Context {
+	ctx := c.makeCopy()
+	ctx.unscoped = true
+	return &ctx
+}
+
+// ForTenant is for creating the same Context, that is restricted to records
+// of the tenant, see rebecca_tenant tag. Tenant-scoped models can not be
+// queried, saved or removed without it
+func (c *Context) ForTenant(tenant interface{}) *Context {
+	ctx := c.makeCopy()
+	ctx.tenant = tenant
+	return &ctx
+}
+
+// WithContext is for creating the same Context with values carried by
+// standard library context, like tenant set with WithTenant
+func (c *Context) WithContext(ctx stdcontext.Context) *Context {
+	if tenant, ok := TenantFrom(ctx); ok {
+		return c.ForTenant(tenant)
+	}
+	return c
+}
+
+// Get is for fetching one record by its primary key within the Context
+func (c *Context) Get(record interface{}, ID interface{}) error {
+	return get(c, ID, record)
+}
+
+// Save is for saving one record (either creating or updating) within the
+// Context. Record of tenant-scoped model gets the tenant of the Context on
+// create, and records of other tenants are refused
+func (c *Context) Save(record interface{}) error {
+	return save(c, record)
+}
+
+// Remove is for removing the record within the Context. Records of other
+// tenants are refused
+func (c *Context) Remove(record interface{}) error {
+	return remove(c, record)
+}
+
+// Load is for fetching associated records of the record, or of the slice of
+// records, with given names of association fields, see rebecca.Load. Names
+// can be nested, like "Posts.Comments"
+func (c *Context) Load(records interface{}, names ...string) error {
+	meta, err := metadataFor(c, records)
+	if err != nil {
+		return err
+	}
+
+	return preloadAssociations(c, &meta, records, names)
+}
+
+// Preload is for creating the same Context, that fetches associated records
+// with given names after All, Where, First and Get, with one query per
+// association for all fetched records, for example:
+//
+//	ctx.Preload("Posts", "Posts.Comments").All(&people)
+func (c *Context) Preload(names ...string) *Context {
+	ctx := c.makeCopy()
+	ctx.preloads = append(append([]string{}, c.preloads...), names...)
+	return &ctx
+}
+
+// All is for fetching all records. records is either a pointer to slice of
+// models or of pointers to models, or a map from primary key to models or to
+// pointers to models
 func (c *Context) All(records interface{}) error {
-	d, lock := driver.Get()
+	if err := c.all(records); err != nil {
+		return err
+	}
+	return c.preload(records)
+}
+
+func (c *Context) all(records interface{}) error {
+	d, lock, err := driverFor(c)
+	if err != nil {
+		return err
+	}
 	defer lock.Unlock()
 
-	meta, err := getMetadata(records)
+	meta, err := metadataFor(c, records)
 	if err != nil {
 		return err
 	}
 
-	fieldss, err := d.All(meta.tablename, meta.fields, c)
+	fields, err := selectedFor(&meta, c.Select, records)
+	if err != nil {
+		return err
+	}
+
+	ctx, err := c.prepared(&meta, d.PlaceholderStyle())
+	if err != nil {
+		return err
+	}
+
+	fieldss, err := d.All(meta.tablename, fields, ctx)
 	if err != nil {
 		return fmt.Errorf("Unable to fetch all records - %s", err)
 	}
 
-	if err := populateRecordsFromFieldss(records, fieldss); err != nil {
+	if err := populateRecordsFromFieldss(&meta, records, fieldss); err != nil {
 		return fmt.Errorf("Unable to fetch all records - %s", err)
 	}
 
 	return nil
 }
 
-// Where is for fetching specific records
-func (c *Context) Where(records interface{}, query string, args ...interface{}) error {
-	d, lock := driver.Get()
+// Where is for fetching specific records. where is either a query with
+// placeholders for args or a structured condition, like rebecca.Eq("age", 12).
+// records are the same as for All
+func (c *Context) Where(records interface{}, where interface{}, args ...interface{}) error {
+	if err := c.where(records, where, args...); err != nil {
+		return err
+	}
+	return c.preload(records)
+}
+
+func (c *Context) where(records interface{}, where interface{}, args ...interface{}) error {
+	d, lock, err := driverFor(c)
+	if err != nil {
+		return err
+	}
 	defer lock.Unlock()
 
-	meta, err := getMetadata(records)
+	meta, err := metadataFor(c, records)
+	if err != nil {
+		return err
+	}
+
+	fields, err := selectedFor(&meta, c.Select, records)
 	if err != nil {
 		return err
 	}
 
-	fieldss, err := d.Where(meta.tablename, meta.fields, c, query, args...)
+	ctx, query, args, err := c.withWhere(&meta, where, args, d.PlaceholderStyle())
+	if err != nil {
+		return err
+	}
+
+	fieldss, err := d.Where(meta.tablename, fields, ctx, query, args...)
 	if err != nil {
 		return fmt.Errorf("Unable to fetch specific records - %s", err)
 	}
 
-	if err := populateRecordsFromFieldss(records, fieldss); err != nil {
+	if err := populateRecordsFromFieldss(&meta, records, fieldss); err != nil {
 		return fmt.Errorf("Unable to fetch specific records - %s", err)
 	}
 
 	return nil
 }
 
-// First is for fetching only one specific record
-func (c *Context) First(record interface{}, query string, args ...interface{}) error {
-	d, lock := driver.Get()
+// First is for fetching only one specific record. where is either a query
+// with placeholders for args or a structured condition
+func (c *Context) First(record interface{}, where interface{}, args ...interface{}) error {
+	if err := c.first(record, where, args...); err != nil {
+		return err
+	}
+	return c.preload(record)
+}
+
+func (c *Context) first(record interface{}, where interface{}, args ...interface{}) error {
+	d, lock, err := driverFor(c)
+	if err != nil {
+		return err
+	}
 	defer lock.Unlock()
 
-	meta, err := getMetadata(record)
+	meta, err := metadataFor(c, record)
 	if err != nil {
 		return err
 	}
 
-	fields, err := d.First(meta.tablename, meta.fields, c, query, args...)
+	selected, err := selectedFields(meta.fields, c.Select)
+	if err != nil {
+		return err
+	}
+
+	ctx, query, args, err := c.withWhere(&meta, where, args, d.PlaceholderStyle())
+	if err != nil {
+		return err
+	}
+
+	fields, err := d.First(meta.tablename, selected, ctx, query, args...)
 	if err != nil {
 		return fmt.Errorf("Unable to fetch specific records - %s", err)
 	}
@@ -160,6 +451,447 @@ func (c *Context) First(record interface{}, query string, args ...interface{}) e
 	return nil
 }
 
+// WhereExample is for fetching records, that are equal to the example in all
+// its non-zero fields. Fields listed in include, by name or by driver name,
+// are required to be equal even when they are zero in the example, and nil
+// ones are required to be NULL
+func (c *Context) WhereExample(records interface{}, example interface{}, include ...string) error {
+	cond, err := exampleCondition(example, include)
+	if err != nil {
+		return err
+	}
+
+	return c.Where(records, cond)
+}
+
+// FirstExample is for fetching only first record, that is equal to the
+// example in all its non-zero fields and in fields listed in include
+func (c *Context) FirstExample(record interface{}, example interface{}, include ...string) error {
+	cond, err := exampleCondition(example, include)
+	if err != nil {
+		return err
+	}
+
+	return c.First(record, cond)
+}
+
+// Iterate is for fetching specific records lazily one by one. Empty where
+// query matches all records. Returned Iterator should be closed after use
+func (c *Context) Iterate(record interface{}, where interface{}, args ...interface{}) (*Iterator, error) {
+	d, lock, err := driverFor(c)
+	if err != nil {
+		return nil, err
+	}
+	defer lock.Unlock()
+
+	meta, err := metadataFor(c, record)
+	if err != nil {
+		return nil, err
+	}
+
+	fields, err := selectedFields(meta.fields, c.Select)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, query, args, err := c.withWhere(&meta, where, args, d.PlaceholderStyle())
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := d.Iterate(meta.tablename, fields, ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("Unable to iterate over records - %s", err)
+	}
+
+	return &Iterator{rows: rows}, nil
+}
+
+// Each is for calling fn for each specific record without loading all of
+// them into memory at once. Empty where query matches all records. fn is
+// required to be of type func(*Model) error, where Model is the type of
+// record. Iteration stops at the first error returned by fn and this error
+// is returned as is
+func (c *Context) Each(record interface{}, where interface{}, args []interface{}, fn interface{}) error {
+	callback, err := recordCallbackFor(record, fn)
+	if err != nil {
+		return err
+	}
+
+	it, err := c.Iterate(record, where, args...)
+	if err != nil {
+		return err
+	}
+	defer it.Close()
+
+	for it.Next() {
+		current := zeroValueOf(record)
+		if err := it.Scan(current); err != nil {
+			return err
+		}
+
+		if err := callback(current); err != nil {
+			return err
+		}
+	}
+
+	return it.Err()
+}
+
+// FindInBatches is for walking through all records in batches of given size,
+// ordered by primary key, Order and OrderBy are ignored. records is required
+// to be a pointer to a slice of models and fn is required to be of type
+// func([]Model) error. Instead of skipping records, each next batch is fetched
+// starting right after the primary key of the last record of the previous
+// batch, so that it stays fast for big tables. Iteration stops at the first
+// error returned by fn and this error is returned as is
+func (c *Context) FindInBatches(records interface{}, size int, fn interface{}) error {
+	if size <= 0 {
+		return fmt.Errorf("Batch size is required to be positive, but got: %d", size)
+	}
+
+	ty := reflect.TypeOf(records)
+	if ty == nil || ty.Kind() != reflect.Ptr || ty.Elem().Kind() != reflect.Slice {
+		return fmt.Errorf("Records are required to be a pointer to slice, but got: %T", records)
+	}
+
+	callback, err := callbackFor(ty.Elem(), fn)
+	if err != nil {
+		return err
+	}
+
+	meta, err := metadataFor(c, records)
+	if err != nil {
+		return err
+	}
+
+	idField := meta.primary
+	if err := ensureHasID(records, idField); err != nil {
+		return err
+	}
+
+	ctx := c.makeCopy()
+	ctx.Select = withSelected(c.Select, idField)
+	ctx.Order = ""
+	ctx.orderBy = []context.Ordering{{Column: idField.DriverName}}
+	ctx.Limit = size
+	ctx.Skip = 0
+	ctx.Offset = 0
+
+	for {
+		batch := reflect.New(ty.Elem())
+		batch.Elem().Set(reflect.MakeSlice(ty.Elem(), 0, size))
+		if err := ctx.All(batch.Interface()); err != nil {
+			return fmt.Errorf("Unable to fetch batch of records - %s", err)
+		}
+
+		count := batch.Elem().Len()
+		if count == 0 {
+			return nil
+		}
+
+		last := batch.Elem().Index(count - 1).Addr().Interface()
+		if err := populateFieldValue(last, &idField); err != nil {
+			return fmt.Errorf("Unable to fetch primary field of the last record in batch - %s", err)
+		}
+
+		if err := callback(batch.Elem().Interface()); err != nil {
+			return err
+		}
+
+		if count < size {
+			return nil
+		}
+
+		ctx.cursor = context.Cursor{{Field: idField}}
+	}
+}
+
+// Count is for counting specific records. Empty where query matches all
+// records. Order, Limit and Skip are ignored. With Distinct, whole distinct
+// records are counted regardless of Select, and with DistinctOn, distinct
+// values of its expressions are counted
+func (c *Context) Count(record interface{}, where interface{}, args ...interface{}) (int, error) {
+	d, lock, err := driverFor(c)
+	if err != nil {
+		return 0, err
+	}
+	defer lock.Unlock()
+
+	meta, err := metadataFor(c, record)
+	if err != nil {
+		return 0, err
+	}
+
+	ctx, query, args, err := c.withWhere(&meta, where, args, d.PlaceholderStyle())
+	if err != nil {
+		return 0, err
+	}
+
+	count, err := d.Count(meta.tablename, ctx, query, args...)
+	if err != nil {
+		return 0, fmt.Errorf("Unable to count records - %s", err)
+	}
+
+	return count, nil
+}
+
+// Paginate is for fetching given page of specific records together with the
+// total count of them. Pages are numbered starting from 1. Empty where query
+// matches all records. Limit and Skip are ignored in favor of page and
+// perPage
+func (c *Context) Paginate(records interface{}, page, perPage int, where interface{}, args ...interface{}) (*Pagination, error) {
+	if page < 1 {
+		return nil, fmt.Errorf("Page is required to be positive, but got: %d", page)
+	}
+
+	if perPage < 1 {
+		return nil, fmt.Errorf("Amount of records per page is required to be positive, but got: %d", perPage)
+	}
+
+	total, err := c.Count(records, where, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx := c.makeCopy()
+	ctx.Limit = perPage
+	ctx.Skip = (page - 1) * perPage
+	ctx.Offset = 0
+
+	if err := ctx.Where(records, where, args...); err != nil {
+		return nil, err
+	}
+
+	return &Pagination{
+		Total:   total,
+		Pages:   (total + perPage - 1) / perPage,
+		Page:    page,
+		PerPage: perPage,
+	}, nil
+}
+
+// After is for requesting the page of records, that comes right after the
+// page with given Page.Next token, it creates new Context. Use it together
+// with Page method
+func (c *Context) After(token string) *Context {
+	ctx := c.makeCopy()
+	ctx.after = token
+	ctx.before = ""
+	return &ctx
+}
+
+// Before is for requesting the page of records, that comes right before the
+// page with given Page.Prev token, it creates new Context. Use it together
+// with Page method
+func (c *Context) Before(token string) *Context {
+	ctx := c.makeCopy()
+	ctx.before = token
+	ctx.after = ""
+	return &ctx
+}
+
+// Page is for fetching one page of specific records using keyset pagination.
+// Empty where query matches all records. Records are ordered by Order, which
+// is required to be a list of columns of the model with optional ASC or DESC,
+// and by primary key for ties. Limit defines page size. Unlike Skip, keyset
+// pagination stays fast for far pages and does not return duplicates when
+// records are inserted concurrently. Returned Page contains opaque tokens for
+// fetching next and previous pages with After and Before
+func (c *Context) Page(records interface{}, where interface{}, args ...interface{}) (*Page, error) {
+	meta, err := metadataFor(c, records)
+	if err != nil {
+		return nil, err
+	}
+
+	columns, err := pageColumnsFor(&meta, c.Order, c.orderBy)
+	if err != nil {
+		return nil, fmt.Errorf("Unable to paginate records - %s", err)
+	}
+
+	ctx := c.makeCopy()
+	ctx.Select = withSelected(c.Select, fieldsOfCursor(columns)...)
+	ctx.Skip = 0
+	ctx.Offset = 0
+	if c.Limit > 0 {
+		ctx.Limit = c.Limit + 1
+	}
+
+	backwards := c.before != ""
+	ctx.Order = ""
+	if backwards {
+		ctx.orderBy = orderingsFor(reversedColumns(columns))
+	} else {
+		ctx.orderBy = orderingsFor(columns)
+	}
+
+	if token := c.after + c.before; token != "" {
+		cursor, err := decodePageToken(&meta, columns, token)
+		if err != nil {
+			return nil, fmt.Errorf("Unable to paginate records - %s", err)
+		}
+
+		if backwards {
+			cursor = reversedColumns(cursor)
+		}
+		ctx.cursor = cursor
+	}
+
+	ty := reflect.TypeOf(records)
+	if ty.Kind() != reflect.Ptr || ty.Elem().Kind() != reflect.Slice {
+		return nil, fmt.Errorf("Records are required to be a pointer to slice, but got: %T", records)
+	}
+
+	found := reflect.New(ty.Elem())
+	found.Elem().Set(reflect.MakeSlice(ty.Elem(), 0, ctx.Limit))
+	if err := ctx.Where(found.Interface(), where, args...); err != nil {
+		return nil, err
+	}
+
+	slice := found.Elem()
+	hasMore := c.Limit > 0 && slice.Len() > c.Limit
+	if hasMore {
+		slice = slice.Slice(0, c.Limit)
+	}
+
+	if backwards {
+		reverseSlice(slice)
+	}
+
+	page := &Page{
+		HasNext: hasMore,
+		HasPrev: c.after != "",
+	}
+
+	if backwards {
+		page.HasNext = true
+		page.HasPrev = hasMore
+	}
+
+	if slice.Len() > 0 {
+		if page.HasNext {
+			last := slice.Index(slice.Len() - 1).Addr().Interface()
+			if page.Next, err = encodePageToken(&meta, columns, last); err != nil {
+				return nil, fmt.Errorf("Unable to paginate records - %s", err)
+			}
+		}
+
+		if page.HasPrev {
+			first := slice.Index(0).Addr().Interface()
+			if page.Prev, err = encodePageToken(&meta, columns, first); err != nil {
+				return nil, fmt.Errorf("Unable to paginate records - %s", err)
+			}
+		}
+	}
+
+	reflect.ValueOf(records).Elem().Set(slice)
+	return page, nil
+}
+
+func (c *Context) withWhere(meta *metadata, where interface{}, args []interface{}, style driver.PlaceholderStyle) (*Context, string, []interface{}, error) {
+	ctx, err := c.prepared(meta, style)
+	if err != nil {
+		return nil, "", nil, err
+	}
+
+	switch where := where.(type) {
+	case string:
+		query, args, err := prepareQuery(where, args, style)
+		if err != nil {
+			return nil, "", nil, err
+		}
+		return ctx, query, args, nil
+
+	case condition.Condition:
+		if len(args) > 0 {
+			return nil, "", nil, fmt.Errorf("Structured condition does not accept arguments, but got: %+v", args)
+		}
+
+		validated, err := validatedCondition(meta, where)
+		if err != nil {
+			return nil, "", nil, err
+		}
+
+		conditionCtx := ctx.makeCopy()
+		conditionCtx.condition = andConditions(ctx.condition, validated)
+		return &conditionCtx, "", nil, nil
+	}
+
+	return nil, "", nil, fmt.Errorf("Where query is required to be either string or structured condition, but got: %T", where)
+}
+
+// prepared checks that records are locked only within transaction, applies
+// tenant and scopes, validates conditions and ordering, resolves named joins
+// of the model and translates placeholders of having query to the style of
+// the driver
+func (c *Context) prepared(meta *metadata, style driver.PlaceholderStyle) (*Context, error) {
+	if !c.Lock.IsZero() && c.tx == nil {
+		return nil, fmt.Errorf("Unable to lock records outside of transaction - %s", c.Lock.Strength)
+	}
+
+	scopes, err := scopesFor(meta, c.scopes, c.unscoped)
+	if err != nil {
+		return nil, err
+	}
+
+	if meta.tenant.Tenant {
+		tenant, err := tenantFor(c, meta)
+		if err != nil {
+			return nil, fmt.Errorf("Unable to query records - %s", err)
+		}
+		scopes = andConditions(Eq(meta.tenant.DriverName, tenant), scopes)
+	}
+
+	if c.Having == "" && len(c.Joins) == 0 && len(c.orderBy) == 0 && scopes == nil && c.condition == nil {
+		return c, nil
+	}
+
+	ctx := c.makeCopy()
+	if ctx.condition, err = validatedCondition(meta, andConditions(c.condition, scopes)); err != nil {
+		return nil, err
+	}
+
+	if len(c.orderBy) > 0 {
+		orderBy, err := orderByFor(meta, c.Order, c.orderBy)
+		if err != nil {
+			return nil, err
+		}
+		ctx.orderBy = orderBy
+	}
+
+	if len(c.Joins) > 0 {
+		joins, err := joinsFor(meta.modelTablename, c.Joins)
+		if err != nil {
+			return nil, err
+		}
+		ctx.Joins = joins
+	}
+
+	if c.Having != "" {
+		having, args, err := prepareQuery(c.Having, c.HavingArgs, style)
+		if err != nil {
+			return nil, fmt.Errorf("Unable to prepare having query - %s", err)
+		}
+		ctx.Having = having
+		ctx.HavingArgs = args
+	}
+
+	return &ctx, nil
+}
+
 func (c Context) makeCopy() Context {
 	return c
 }
+
+func (c *Context) preload(records interface{}) error {
+	if len(c.preloads) == 0 {
+		return nil
+	}
+
+	meta, err := metadataFor(c, records)
+	if err != nil {
+		return err
+	}
+
+	return preloadAssociations(c, &meta, records, c.preloads)
+}
@@ -1,17 +1,141 @@
 package context
 
+import (
+	"github.com/waterlink/rebecca/condition"
+	"github.com/waterlink/rebecca/field"
+)
+
 // Context is for representing querying context.
 // It is required for implementation of orderby, groupby, limit and skip.
 type Context interface {
 	GetOrder() string
+	GetOrderBy() []Ordering
 	GetGroup() string
 	GetLimit() int
 	GetSkip() int
 	GetTx() interface{}
+	GetCursor() Cursor
+	GetCondition() condition.Condition
+	GetJoins() []string
+	GetHaving() string
+	GetHavingArgs() []interface{}
+	GetDistinct() bool
+	GetDistinctOn() []string
+	GetLock() Lock
 
 	SetOrder(string) Context
+	SetOrderBy([]Ordering) Context
 	SetGroup(string) Context
 	SetLimit(int) Context
 	SetSkip(int) Context
 	SetTx(interface{}) Context
+	SetCursor(Cursor) Context
+	SetCondition(condition.Condition) Context
+	SetJoins([]string) Context
+	SetHaving(string, ...interface{}) Context
+	SetDistinct(bool) Context
+	SetDistinctOn([]string) Context
+	SetLock(Lock) Context
+}
+
+// CursorField is for representing one field of the Cursor together with the
+// direction of ordering by this field
+type CursorField struct {
+	field.Field
+	Descending bool
+}
+
+// Cursor is for representing a position for keyset pagination. When it is
+// present, drivers are required to return only records that come strictly
+// after this position, i.e. when values of cursor fields, compared one by one
+// in given directions, are greater than values of the cursor
+type Cursor []CursorField
+
+// Nulls is for representing where NULL values are placed by Ordering
+type Nulls string
+
+// Available placements of NULL values
+const (
+	// NullsDefault is for placing NULL values as database does by default:
+	// as if they are greater than any other value
+	NullsDefault Nulls = ""
+
+	// NullsFirst is for placing NULL values before other values
+	NullsFirst Nulls = "NULLS FIRST"
+
+	// NullsLast is for placing NULL values after other values
+	NullsLast Nulls = "NULLS LAST"
+)
+
+// Ordering is for representing ordering by one column of the model, which,
+// unlike raw order, is safe for drivers to render
+type Ordering struct {
+	Column     string
+	Descending bool
+	Nulls      Nulls
+}
+
+// NullsFirst is for creating the same Ordering, that places NULL values
+// first
+func (o Ordering) NullsFirst() Ordering {
+	o.Nulls = NullsFirst
+	return o
+}
+
+// NullsLast is for creating the same Ordering, that places NULL values last
+func (o Ordering) NullsLast() Ordering {
+	o.Nulls = NullsLast
+	return o
+}
+
+// LockStrength is for representing how strong the row lock is
+type LockStrength string
+
+// Available lock strengths
+const (
+	// ForUpdate is for exclusive lock, that conflicts with any other lock
+	ForUpdate LockStrength = "FOR UPDATE"
+
+	// ForShare is for shared lock, that conflicts only with exclusive locks
+	ForShare LockStrength = "FOR SHARE"
+)
+
+// LockWait is for representing what to do with rows, that are already
+// locked by another transaction
+type LockWait string
+
+// Available lock wait policies
+const (
+	// Wait is for waiting until conflicting locks are released
+	Wait LockWait = ""
+
+	// NoWait is for failing immediately on conflicting lock
+	NoWait LockWait = "NOWAIT"
+
+	// SkipLocked is for skipping rows with conflicting locks
+	SkipLocked LockWait = "SKIP LOCKED"
+)
+
+// Lock is for representing row locking clause of the query. Zero Lock means
+// that rows are not locked
+type Lock struct {
+	Strength LockStrength
+	Wait     LockWait
+}
+
+// NoWait is for creating the same Lock, that fails immediately on conflict
+func (l Lock) NoWait() Lock {
+	l.Wait = NoWait
+	return l
+}
+
+// SkipLocked is for creating the same Lock, that skips already locked rows
+func (l Lock) SkipLocked() Lock {
+	l.Wait = SkipLocked
+	return l
+}
+
+// IsZero is for checking if Lock does not lock anything
+func (l Lock) IsZero() bool {
+	return l.Strength == ""
 }
@@ -6,6 +6,7 @@ import (
 	"testing"
 
 	"github.com/waterlink/rebecca"
+	"github.com/waterlink/rebecca/context"
 )
 
 func ExampleContext_All() {
@@ -68,6 +69,74 @@ func ExampleContext_Where() {
 	fmt.Print(teenagers)
 }
 
+func ExampleContext_Each() {
+	type Person struct {
+		// ...
+	}
+
+	ctx := rebecca.Context{Order: "age DESC"}
+	err := ctx.Each(&Person{}, "age < $1", []interface{}{21}, func(p *Person) error {
+		// Records are fetched lazily, one by one, so it is possible to go
+		// through huge tables here without loading them into memory.
+		fmt.Print(p)
+		return nil
+	})
+	if err != nil {
+		panic(err)
+	}
+}
+
+func ExampleContext_Iterate() {
+	type Person struct {
+		// ...
+	}
+
+	ctx := rebecca.Context{Order: "age DESC"}
+	it, err := ctx.Iterate(&Person{}, "")
+	if err != nil {
+		panic(err)
+	}
+	defer it.Close()
+
+	for it.Next() {
+		person := &Person{}
+		if err := it.Scan(person); err != nil {
+			panic(err)
+		}
+		// At this point person contains current record, records are sorted by
+		// age in descending order.
+		fmt.Print(person)
+	}
+
+	if err := it.Err(); err != nil {
+		panic(err)
+	}
+}
+
+func ExampleContext_Page() {
+	type Person struct {
+		// ...
+	}
+
+	ctx := &rebecca.Context{Order: "age DESC", Limit: 20}
+	people := []Person{}
+	page, err := ctx.Page(&people, "age < $1", 21)
+	if err != nil {
+		panic(err)
+	}
+	// At this point people contains first 20 Person records of age < 21
+	// sorted by age in descending order.
+	fmt.Print(people)
+
+	if page.HasNext {
+		// And this is how next 20 records are fetched:
+		if _, err := ctx.After(page.Next).Page(&people, "age < $1", 21); err != nil {
+			panic(err)
+		}
+		fmt.Print(people)
+	}
+}
+
 func TestContextGetters(t *testing.T) {
 	examples := map[string]struct {
 		ctx      *rebecca.Context
@@ -91,6 +160,21 @@ func TestContextGetters(t *testing.T) {
 			expected: []string{"hello DESC", "id ASC"},
 		},
 
+		"GetOrderBy after OrderBy": {
+			ctx: &rebecca.Context{},
+			action: func(ctx *rebecca.Context) interface{} {
+				x := ctx.OrderBy(rebecca.Asc("age"), rebecca.Desc("name").NullsLast())
+				return [][]context.Ordering{ctx.GetOrderBy(), x.GetOrderBy()}
+			},
+			expected: [][]context.Ordering{
+				nil,
+				{
+					{Column: "age"},
+					{Column: "name", Descending: true, Nulls: context.NullsLast},
+				},
+			},
+		},
+
 		"GetGroup": {
 			ctx: &rebecca.Context{Group: "age"},
 			action: func(ctx *rebecca.Context) interface{} {
@@ -158,6 +242,62 @@ func TestContextGetters(t *testing.T) {
 			expected: 40,
 		},
 
+		"GetJoins after SetJoins": {
+			ctx: &rebecca.Context{Joins: []string{"author"}},
+			action: func(ctx *rebecca.Context) interface{} {
+				x := ctx.SetJoins([]string{"comments"})
+				return [][]string{ctx.GetJoins(), x.GetJoins()}
+			},
+			expected: [][]string{{"author"}, {"comments"}},
+		},
+
+		"GetHaving": {
+			ctx: &rebecca.Context{Having: "count(*) > $1", HavingArgs: []interface{}{2}},
+			action: func(ctx *rebecca.Context) interface{} {
+				return []interface{}{ctx.GetHaving(), ctx.GetHavingArgs()}
+			},
+			expected: []interface{}{"count(*) > $1", []interface{}{2}},
+		},
+
+		"GetHaving after SetHaving": {
+			ctx: &rebecca.Context{Having: "count(*) > $1", HavingArgs: []interface{}{2}},
+			action: func(ctx *rebecca.Context) interface{} {
+				x := ctx.SetHaving("max(age) > $1", 21)
+				return []interface{}{ctx.GetHaving(), x.GetHaving(), x.GetHavingArgs()}
+			},
+			expected: []interface{}{"count(*) > $1", "max(age) > $1", []interface{}{21}},
+		},
+
+		"GetDistinct after SetDistinct": {
+			ctx: &rebecca.Context{},
+			action: func(ctx *rebecca.Context) interface{} {
+				x := ctx.SetDistinct(true)
+				return []bool{ctx.GetDistinct(), x.GetDistinct()}
+			},
+			expected: []bool{false, true},
+		},
+
+		"GetDistinctOn after SetDistinctOn": {
+			ctx: &rebecca.Context{DistinctOn: []string{"name"}},
+			action: func(ctx *rebecca.Context) interface{} {
+				x := ctx.SetDistinctOn([]string{"age"})
+				return [][]string{ctx.GetDistinctOn(), x.GetDistinctOn()}
+			},
+			expected: [][]string{{"name"}, {"age"}},
+		},
+
+		"GetLock after SetLock": {
+			ctx: &rebecca.Context{Lock: rebecca.ForShare},
+			action: func(ctx *rebecca.Context) interface{} {
+				x := ctx.SetLock(rebecca.ForUpdate.SkipLocked())
+				return []context.Lock{ctx.GetLock(), x.GetLock()}
+			},
+			expected: []context.Lock{
+				{Strength: context.ForShare},
+				{Strength: context.ForUpdate, Wait: context.SkipLocked},
+			},
+		},
+
 		"GetTx": {
 			ctx: &rebecca.Context{},
 			action: func(ctx *rebecca.Context) interface{} {
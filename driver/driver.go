@@ -3,6 +3,7 @@ package driver
 import (
 	"sync"
 
+	"github.com/waterlink/rebecca/condition"
 	"github.com/waterlink/rebecca/context"
 	"github.com/waterlink/rebecca/field"
 )
@@ -26,6 +27,77 @@ type Driver interface {
 	Rollback(tx interface{})
 	Commit(tx interface{}) error
 	Exec(tx interface{}, query string, args ...interface{}) error
+	Iterate(tablename string, fields []field.Field, ctx context.Context, where string, args ...interface{}) (Rows, error)
+
+	// Count counts records matching where, it counts all records when where
+	// is empty
+	Count(tablename string, ctx context.Context, where string, args ...interface{}) (int, error)
+
+	PlaceholderStyle() PlaceholderStyle
+}
+
+// PlaceholderStyle is for describing how driver expects placeholders for
+// arguments to look like in queries. Queries are translated by rebecca to the
+// style of the active driver before they are passed to it
+type PlaceholderStyle int
+
+// Available placeholder styles
+const (
+	// Dollar is for numbered placeholders: $1, $2, ...
+	Dollar PlaceholderStyle = iota
+
+	// Question is for positional placeholders: ?, ?, ...
+	Question
+)
+
+// Rows is for iterating over query results lazily, one record at a time. It
+// is returned by Driver.Iterate, which iterates over all records when where
+// is empty
+type Rows interface {
+	// Next advances to the next record, it returns false when there are no
+	// more records
+	Next() bool
+
+	// Fields returns the current record
+	Fields() ([]field.Field, error)
+
+	// Close releases underlying resources, it is safe to call it more than
+	// once
+	Close() error
+
+	// Err returns an error, that was encountered during iteration, if any
+	Err() error
+}
+
+// TenantDriver is for drivers, that keep records of each tenant separately,
+// for example, in its own schema. Queries within Context of the tenant are
+// routed to the driver returned by ForTenant
+type TenantDriver interface {
+	Driver
+
+	// ForTenant returns the driver, that routes queries to the storage of
+	// the tenant
+	ForTenant(tenant interface{}) (Driver, error)
+
+	// CreateTenant creates the storage of the tenant, for example, its
+	// schema, with given tables
+	CreateTenant(tenant interface{}, tablenames []string) error
+}
+
+// ScopedDriver is for drivers, that can restrict updating and removing of the
+// record to the case when it matches the condition, within the same query. It
+// is required for tenant-scoped models, so that the record of another tenant
+// can not be changed between the check and the change
+type ScopedDriver interface {
+	Driver
+
+	// UpdateWhere updates the record given its ID, when it matches cond. It
+	// returns the number of updated records
+	UpdateWhere(tx interface{}, tablename string, fields []field.Field, ID field.Field, cond condition.Condition) (int, error)
+
+	// RemoveWhere removes the record given its ID, when it matches cond. It
+	// returns the number of removed records
+	RemoveWhere(tx interface{}, tablename string, ID field.Field, cond condition.Condition) (int, error)
 }
 
 // SetupDriver is for setting up driver manually
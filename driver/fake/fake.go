@@ -1,12 +1,22 @@
 // Package fake is a limited in-memory implementation of rebecca.Driver
-// It does not implement any rebecca.Context features.
+// Out of rebecca.Context features it implements only ordering, grouping and
+// distinct by plain columns, having queries registered with RegisterHaving,
+// limit, skip, keyset pagination cursor and structured conditions.
 package fake
 
 import (
+	sqldriver "database/sql/driver"
 	"errors"
 	"fmt"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
 
+	"github.com/waterlink/rebecca/condition"
 	"github.com/waterlink/rebecca/context"
+	"github.com/waterlink/rebecca/driver"
 	"github.com/waterlink/rebecca/field"
 )
 
@@ -20,22 +30,28 @@ type ReceivedExec struct {
 // Driver represents fake driver for tests
 type Driver struct {
 	whereRegistry    map[string]func([]field.Field, ...interface{}) (bool, error)
+	havingRegistry   map[string]func([][]field.Field, ...interface{}) (bool, error)
 	records          map[string][][]field.Field
 	maxID            int
 	createdIDs       map[int]struct{}
 	updatedIDs       map[int]struct{}
 	removedIDs       map[field.Field]string
 	lastReceivedExec ReceivedExec
+	placeholderStyle driver.PlaceholderStyle
+	locks            *lockTable
+	parent           *Driver
 }
 
 // NewDriver is for creating new fake driver
 func NewDriver() *Driver {
 	return &Driver{
-		whereRegistry: map[string]func([]field.Field, ...interface{}) (bool, error){},
-		records:       map[string][][]field.Field{},
-		createdIDs:    map[int]struct{}{},
-		updatedIDs:    map[int]struct{}{},
-		removedIDs:    map[field.Field]string{},
+		whereRegistry:  map[string]func([]field.Field, ...interface{}) (bool, error){},
+		havingRegistry: map[string]func([][]field.Field, ...interface{}) (bool, error){},
+		records:        map[string][][]field.Field{},
+		createdIDs:     map[int]struct{}{},
+		updatedIDs:     map[int]struct{}{},
+		removedIDs:     map[field.Field]string{},
+		locks:          newLockTable(),
 	}
 }
 
@@ -71,7 +87,7 @@ func (d *Driver) Create(tx interface{}, tablename string, fields []field.Field,
 // Update is for updating existing record
 func (d *Driver) Update(tx interface{}, tablename string, fields []field.Field, ID field.Field) error {
 	if tx != nil {
-		return tx.(*Driver).Update(tx, tablename, fields, ID)
+		return tx.(*Driver).Update(nil, tablename, fields, ID)
 	}
 
 	records := d.getTable(tablename)
@@ -92,15 +108,21 @@ func (d *Driver) All(tablename string, fields []field.Field, ctx context.Context
 		return tx.(*Driver).All(tablename, fields, ctx.SetTx(nil))
 	}
 
-	return d.getTable(tablename), nil
+	return d.applyContext(tablename, d.getTable(tablename), fields, ctx)
 }
 
-// Where is for fetching specific records
+// Where is for fetching specific records. Where queries are required to be
+// registered with RegisterWhere beforehand, empty where query matches all
+// records
 func (d *Driver) Where(tablename string, fields []field.Field, ctx context.Context, where string, args ...interface{}) ([][]field.Field, error) {
 	if tx := ctx.GetTx(); tx != nil {
 		return tx.(*Driver).Where(tablename, fields, ctx.SetTx(nil), where, args...)
 	}
 
+	if where == "" {
+		return d.All(tablename, fields, ctx)
+	}
+
 	result := [][]field.Field{}
 
 	fn, ok := d.whereRegistry[where]
@@ -122,7 +144,7 @@ func (d *Driver) Where(tablename string, fields []field.Field, ctx context.Conte
 		}
 	}
 
-	return result, nil
+	return d.applyContext(tablename, result, fields, ctx)
 }
 
 // First is for fetching first specific record
@@ -131,7 +153,7 @@ func (d *Driver) First(tablename string, fields []field.Field, ctx context.Conte
 		return tx.(*Driver).First(tablename, fields, ctx.SetTx(nil), where, args...)
 	}
 
-	records, err := d.Where(tablename, fields, ctx, where, args...)
+	records, err := d.Where(tablename, fields, ctx.SetLimit(1), where, args...)
 	if err != nil {
 		return nil, fmt.Errorf("Unable to get first record - %s", err)
 	}
@@ -143,6 +165,32 @@ func (d *Driver) First(tablename string, fields []field.Field, ctx context.Conte
 	return records[0], nil
 }
 
+// UpdateWhere is for updating existing record, only when it satisfies cond. It
+// returns the number of updated records
+func (d *Driver) UpdateWhere(tx interface{}, tablename string, fields []field.Field, ID field.Field, cond condition.Condition) (int, error) {
+	if tx != nil {
+		return tx.(*Driver).UpdateWhere(nil, tablename, fields, ID, cond)
+	}
+
+	records := d.getTable(tablename)
+	for i, record := range records {
+		if !hasField(record, ID) {
+			continue
+		}
+
+		ok, err := satisfies(record, cond)
+		if err != nil || !ok {
+			return 0, err
+		}
+
+		records[i] = fields
+		d.updatedIDs[ID.Value.(int)] = struct{}{}
+		return 1, nil
+	}
+
+	return 0, nil
+}
+
 // Remove is for removing record by provided ID from database
 func (d *Driver) Remove(tx interface{}, tablename string, ID field.Field) error {
 	if tx != nil {
@@ -162,21 +210,63 @@ func (d *Driver) Remove(tx interface{}, tablename string, ID field.Field) error
 	return nil
 }
 
+// RemoveWhere is for removing record by provided ID, only when it satisfies
+// cond. It returns the number of removed records
+func (d *Driver) RemoveWhere(tx interface{}, tablename string, ID field.Field, cond condition.Condition) (int, error) {
+	if tx != nil {
+		return tx.(*Driver).RemoveWhere(nil, tablename, ID, cond)
+	}
+
+	for _, record := range d.getTable(tablename) {
+		if !hasField(record, ID) {
+			continue
+		}
+
+		ok, err := satisfies(record, cond)
+		if err != nil || !ok {
+			return 0, err
+		}
+
+		if err := d.Remove(nil, tablename, ID); err != nil {
+			return 0, err
+		}
+		return 1, nil
+	}
+
+	return 0, nil
+}
+
 // HasTransactions indicates transaction support of the driver
 func (d *Driver) HasTransactions() bool {
 	return true
 }
 
+// PlaceholderStyle is for telling what placeholders the driver expects in
+// queries, it is driver.Dollar unless configured with SetPlaceholderStyle
+func (d *Driver) PlaceholderStyle() driver.PlaceholderStyle {
+	return d.placeholderStyle
+}
+
+// SetPlaceholderStyle is for configuring what placeholders the driver
+// expects in queries. Where queries are registered with RegisterWhere in
+// this style
+func (d *Driver) SetPlaceholderStyle(style driver.PlaceholderStyle) {
+	d.placeholderStyle = style
+}
+
 // Begin is for starting new transaction and returning relevant state
 func (d *Driver) Begin() (interface{}, error) {
 	//return nil, errors.New("fakedriver does not support transactions")
 	tx := &Driver{
-		whereRegistry: d.whereRegistry,
-		records:       map[string][][]field.Field{},
-		maxID:         d.maxID,
-		createdIDs:    map[int]struct{}{},
-		updatedIDs:    map[int]struct{}{},
-		removedIDs:    map[field.Field]string{},
+		whereRegistry:  d.whereRegistry,
+		havingRegistry: d.havingRegistry,
+		records:        map[string][][]field.Field{},
+		maxID:          d.maxID,
+		createdIDs:     map[int]struct{}{},
+		updatedIDs:     map[int]struct{}{},
+		removedIDs:     map[field.Field]string{},
+		locks:          d.locks,
+		parent:         d,
 	}
 	d.maxID = d.maxID + 1000
 
@@ -196,8 +286,13 @@ func (d *Driver) Begin() (interface{}, error) {
 	return tx, nil
 }
 
-// Rollback is for rolling back the transaction
-func (d *Driver) Rollback(interface{}) {}
+// Rollback is for rolling back the transaction, it releases locks of the
+// transaction
+func (d *Driver) Rollback(tx interface{}) {
+	if tx != nil {
+		d.locks.release(tx.(*Driver))
+	}
+}
 
 // Commit is for committing the transaction
 func (d *Driver) Commit(tx interface{}) error {
@@ -206,6 +301,8 @@ func (d *Driver) Commit(tx interface{}) error {
 	}
 
 	dtx := tx.(*Driver)
+	defer d.locks.release(dtx)
+
 	for tablename, table := range dtx.records {
 		for _, row := range table {
 			id := getPrimary(row)
@@ -240,16 +337,71 @@ func (d *Driver) Exec(tx interface{}, query string, args ...interface{}) error {
 	return nil
 }
 
+// Iterate is for iterating over specific records one by one. Empty where
+// query matches all records
+func (d *Driver) Iterate(tablename string, fields []field.Field, ctx context.Context, where string, args ...interface{}) (driver.Rows, error) {
+	records, err := d.Where(tablename, fields, ctx, where, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	return &rows{records: records, current: -1}, nil
+}
+
+// Count is for counting specific records. Empty where query matches all
+// records
+func (d *Driver) Count(tablename string, ctx context.Context, where string, args ...interface{}) (int, error) {
+	records, err := d.Where(tablename, nil, ctx.SetLimit(0).SetSkip(0).SetLock(context.Lock{}), where, args...)
+	if err != nil {
+		return 0, err
+	}
+
+	return len(records), nil
+}
+
 // RegisterWhere is for registering fake where query
 func (d *Driver) RegisterWhere(where string, fn func([]field.Field, ...interface{}) (bool, error)) {
 	d.whereRegistry[where] = fn
 }
 
+// RegisterHaving is for registering fake having query, fn receives all
+// records of the group
+func (d *Driver) RegisterHaving(having string, fn func([][]field.Field, ...interface{}) (bool, error)) {
+	d.havingRegistry[having] = fn
+}
+
 // ReceivedExec is for fetching last executed query
 func (d *Driver) ReceivedExec() ReceivedExec {
 	return d.lastReceivedExec
 }
 
+type rows struct {
+	records [][]field.Field
+	current int
+}
+
+func (r *rows) Next() bool {
+	if r.current+1 >= len(r.records) {
+		return false
+	}
+
+	r.current++
+	return true
+}
+
+func (r *rows) Fields() ([]field.Field, error) {
+	return r.records[r.current], nil
+}
+
+func (r *rows) Close() error {
+	r.current = len(r.records)
+	return nil
+}
+
+func (r *rows) Err() error {
+	return nil
+}
+
 func (d *Driver) ensureTable(name string) {
 	_, ok := d.records[name]
 	if !ok {
@@ -267,6 +419,655 @@ func (d *Driver) insertTo(table string, fields []field.Field) {
 	d.records[table] = append(d.records[table], fields)
 }
 
+func (d *Driver) applyContext(tablename string, records [][]field.Field, fields []field.Field, ctx context.Context) ([][]field.Field, error) {
+	if joins := ctx.GetJoins(); len(joins) > 0 {
+		return nil, fmt.Errorf("Fake driver does not support joins, but got: %+v", joins)
+	}
+
+	result := [][]field.Field{}
+
+	cursor := ctx.GetCursor()
+	cond := ctx.GetCondition()
+	for _, record := range records {
+		ok, err := isAfterCursor(record, cursor)
+		if err != nil {
+			return nil, err
+		}
+
+		if ok && cond != nil {
+			if ok, err = satisfies(record, cond); err != nil {
+				return nil, err
+			}
+		}
+
+		if ok {
+			result = append(result, record)
+		}
+	}
+
+	if ctx.GetGroup() != "" || ctx.GetHaving() != "" {
+		grouped, err := d.groupRecords(result, ctx)
+		if err != nil {
+			return nil, err
+		}
+		result = grouped
+	}
+
+	if err := sortRecords(result, ctx); err != nil {
+		return nil, err
+	}
+
+	if distinctOn := ctx.GetDistinctOn(); len(distinctOn) > 0 {
+		distinct, err := distinctRecords(result, distinctOn)
+		if err != nil {
+			return nil, err
+		}
+		result = distinct
+	}
+
+	if lock := ctx.GetLock(); !lock.IsZero() {
+		locked, err := d.lockRecords(tablename, result, lock, cond, ctx.GetSkip(), ctx.GetLimit())
+		if err != nil {
+			return nil, err
+		}
+		result = locked
+		ctx = ctx.SetSkip(0).SetLimit(0)
+	}
+
+	if fields != nil {
+		result = projectRecords(result, fields)
+	}
+
+	if ctx.GetDistinct() {
+		if !ctx.GetLock().IsZero() {
+			return nil, fmt.Errorf("Fake driver does not support %s with distinct", ctx.GetLock().Strength)
+		}
+
+		distinct, err := distinctRecords(result, nil)
+		if err != nil {
+			return nil, err
+		}
+		result = distinct
+	}
+
+	if skip := ctx.GetSkip(); skip > 0 {
+		if skip > len(result) {
+			skip = len(result)
+		}
+		result = result[skip:]
+	}
+
+	if limit := ctx.GetLimit(); limit > 0 && limit < len(result) {
+		result = result[:limit]
+	}
+
+	return result, nil
+}
+
+// groupRecords groups records by plain columns and filters groups with
+// registered having query. Each group is represented by its first record
+func (d *Driver) groupRecords(records [][]field.Field, ctx context.Context) ([][]field.Field, error) {
+	columns := []string{}
+	if group := ctx.GetGroup(); group != "" {
+		for _, column := range strings.Split(group, ",") {
+			columns = append(columns, strings.TrimSpace(column))
+		}
+	}
+
+	groups := [][][]field.Field{}
+	keys := [][]interface{}{}
+	for _, record := range records {
+		key, err := recordKey(record, columns)
+		if err != nil {
+			return nil, fmt.Errorf("Fake driver supports only grouping by plain columns - %s", err)
+		}
+
+		found := false
+		for i := range keys {
+			if reflect.DeepEqual(keys[i], key) {
+				groups[i] = append(groups[i], record)
+				found = true
+				break
+			}
+		}
+
+		if !found {
+			keys = append(keys, key)
+			groups = append(groups, [][]field.Field{record})
+		}
+	}
+
+	having := ctx.GetHaving()
+	fn, ok := d.havingRegistry[having]
+	if having != "" && !ok {
+		return nil, fmt.Errorf(
+			"Fake driver has no '%s' having query registered, please register with RegisterHaving",
+			having,
+		)
+	}
+
+	result := [][]field.Field{}
+	for _, group := range groups {
+		if having != "" {
+			ok, err := fn(group, ctx.GetHavingArgs()...)
+			if err != nil {
+				return nil, fmt.Errorf("Registered having query '%s' returned error - %s", having, err)
+			}
+
+			if !ok {
+				continue
+			}
+		}
+
+		result = append(result, group[0])
+	}
+
+	return result, nil
+}
+
+// lockRecords locks records for the transaction, while applying skip and
+// limit, so that records skipped because of SkipLocked are not counted. Like
+// postgres, it returns the latest committed version of each locked record,
+// that still satisfies cond, instead of the one from the snapshot of the
+// transaction, so that waiting for the lock does not lead to lost updates
+func (d *Driver) lockRecords(tablename string, records [][]field.Field, lock context.Lock, cond condition.Condition, skip, limit int) ([][]field.Field, error) {
+	if d.parent == nil {
+		return nil, errors.New("Fake driver is unable to lock records outside of transaction")
+	}
+
+	result := [][]field.Field{}
+	for _, record := range records {
+		if limit > 0 && len(result) >= limit {
+			break
+		}
+
+		key := fmt.Sprintf("%s:%v", tablename, getPrimary(record).Value)
+		ok, err := d.locks.acquire(d, key, lock)
+		if err != nil {
+			return nil, err
+		}
+
+		if !ok {
+			continue
+		}
+
+		record, ok = d.refreshRecord(tablename, record)
+		if ok && cond != nil {
+			if ok, err = satisfies(record, cond); err != nil {
+				return nil, err
+			}
+		}
+
+		if !ok {
+			continue
+		}
+
+		if skip > 0 {
+			skip--
+			continue
+		}
+
+		result = append(result, record)
+	}
+
+	return result, nil
+}
+
+// refreshRecord replaces the record in the snapshot of the transaction with
+// its latest committed version, unless the transaction has changed it
+// itself. It returns false when the record was removed after the snapshot.
+// Committed version is read from the driver, that has started the
+// transaction, rather than from the active driver, because lock of the
+// active driver is not held while waiting for row locks
+func (d *Driver) refreshRecord(tablename string, record []field.Field) ([]field.Field, bool) {
+	ID := getPrimary(record)
+	if _, ok := d.updatedIDs[ID.Value.(int)]; ok {
+		return record, true
+	}
+
+	table := d.getTable(tablename)
+	for _, committed := range d.parent.getTable(tablename) {
+		if !hasField(committed, ID) {
+			continue
+		}
+
+		fresh := append([]field.Field{}, committed...)
+		for i, row := range table {
+			if hasField(row, ID) {
+				table[i] = fresh
+			}
+		}
+		return fresh, true
+	}
+
+	return nil, false
+}
+
+// distinctRecords keeps only first record for each distinct combination of
+// values of columns, or of all fields when columns are empty
+func distinctRecords(records [][]field.Field, columns []string) ([][]field.Field, error) {
+	result := [][]field.Field{}
+	keys := [][]interface{}{}
+
+	for _, record := range records {
+		key, err := recordKey(record, columns)
+		if err != nil {
+			return nil, fmt.Errorf("Fake driver supports only distinct on plain columns - %s", err)
+		}
+
+		found := false
+		for _, other := range keys {
+			if reflect.DeepEqual(other, key) {
+				found = true
+				break
+			}
+		}
+
+		if !found {
+			keys = append(keys, key)
+			result = append(result, record)
+		}
+	}
+
+	return result, nil
+}
+
+func recordKey(record []field.Field, columns []string) ([]interface{}, error) {
+	key := []interface{}{}
+
+	if len(columns) == 0 {
+		for _, f := range record {
+			key = append(key, f.Value)
+		}
+		return key, nil
+	}
+
+	for _, column := range columns {
+		f, ok := findField(record, column)
+		if !ok {
+			return nil, fmt.Errorf("unknown field %s", column)
+		}
+		key = append(key, f.Value)
+	}
+
+	return key, nil
+}
+
+// projectRecords leaves only requested fields in records
+func projectRecords(records [][]field.Field, fields []field.Field) [][]field.Field {
+	result := [][]field.Field{}
+	for _, record := range records {
+		projected := []field.Field{}
+		for _, f := range fields {
+			if found, ok := findField(record, f.DriverName); ok {
+				projected = append(projected, found)
+			}
+		}
+		result = append(result, projected)
+	}
+	return result
+}
+
+func satisfies(record []field.Field, cond condition.Condition) (bool, error) {
+	switch c := cond.(type) {
+	case condition.Compare:
+		f, ok := findField(record, c.Column)
+		if !ok {
+			return false, fmt.Errorf("Fake driver is unable to find condition field %s", c.Column)
+		}
+		return compareWith(f.Value, c.Operator, c.Value)
+
+	case condition.In:
+		f, ok := findField(record, c.Column)
+		if !ok {
+			return false, fmt.Errorf("Fake driver is unable to find condition field %s", c.Column)
+		}
+
+		for _, value := range c.Values {
+			ok, err := compareWith(f.Value, condition.Eq, value)
+			if err != nil {
+				return false, err
+			}
+
+			if ok {
+				return true, nil
+			}
+		}
+		return false, nil
+
+	case condition.Like:
+		f, ok := findField(record, c.Column)
+		if !ok {
+			return false, fmt.Errorf("Fake driver is unable to find condition field %s", c.Column)
+		}
+
+		value, ok := plainValue(f.Value).(string)
+		if !ok {
+			return false, fmt.Errorf("Fake driver is unable to match non-string field %s against LIKE pattern", c.Column)
+		}
+		return likePattern(c.Pattern).MatchString(value), nil
+
+	case condition.IsNull:
+		f, ok := findField(record, c.Column)
+		if !ok {
+			return false, fmt.Errorf("Fake driver is unable to find condition field %s", c.Column)
+		}
+		return isNull(f.Value), nil
+
+	case condition.And:
+		for _, inner := range c {
+			if ok, err := satisfies(record, inner); err != nil || !ok {
+				return false, err
+			}
+		}
+		return true, nil
+
+	case condition.Or:
+		for _, inner := range c {
+			if ok, err := satisfies(record, inner); err != nil || ok {
+				return ok, err
+			}
+		}
+		return false, nil
+
+	case condition.Not:
+		ok, err := satisfies(record, c.Condition)
+		return !ok, err
+	}
+
+	return true, nil
+}
+
+func compareWith(l interface{}, op condition.Operator, r interface{}) (bool, error) {
+	l = plainValue(l)
+	r = plainValue(r)
+
+	if l == nil || r == nil {
+		// Comparison with NULL is never satisfied, as in SQL
+		return false, nil
+	}
+
+	cmp, err := compareValues(l, r)
+	if err != nil {
+		if op != condition.Eq && op != condition.NotEq {
+			return false, err
+		}
+
+		cmp = 1
+		if reflect.DeepEqual(l, r) {
+			cmp = 0
+		}
+	}
+
+	switch op {
+	case condition.Eq:
+		return cmp == 0, nil
+	case condition.NotEq:
+		return cmp != 0, nil
+	case condition.Lt:
+		return cmp < 0, nil
+	case condition.Lte:
+		return cmp <= 0, nil
+	case condition.Gt:
+		return cmp > 0, nil
+	case condition.Gte:
+		return cmp >= 0, nil
+	}
+
+	return false, fmt.Errorf("Fake driver does not know operator %s", op)
+}
+
+func plainValue(value interface{}) interface{} {
+	if valuer, ok := value.(sqldriver.Valuer); ok {
+		if v := reflect.ValueOf(valuer); v.Kind() == reflect.Ptr && v.IsNil() {
+			return nil
+		}
+
+		plain, err := valuer.Value()
+		if err != nil {
+			return value
+		}
+		return plain
+	}
+
+	v := reflect.ValueOf(value)
+	if v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			return nil
+		}
+		return plainValue(v.Elem().Interface())
+	}
+
+	return value
+}
+
+func isNull(value interface{}) bool {
+	value = plainValue(value)
+	if value == nil {
+		return true
+	}
+
+	v := reflect.ValueOf(value)
+	switch v.Kind() {
+	case reflect.Interface, reflect.Map, reflect.Slice:
+		return v.IsNil()
+	}
+	return false
+}
+
+func likePattern(pattern string) *regexp.Regexp {
+	expr := ""
+	for _, r := range pattern {
+		switch r {
+		case '%':
+			expr += ".*"
+		case '_':
+			expr += "."
+		default:
+			expr += regexp.QuoteMeta(string(r))
+		}
+	}
+	return regexp.MustCompile("(?s)^" + expr + "$")
+}
+
+func isAfterCursor(record []field.Field, cursor context.Cursor) (bool, error) {
+	if len(cursor) == 0 {
+		return true, nil
+	}
+
+	for _, c := range cursor {
+		f, ok := findField(record, c.DriverName)
+		if !ok {
+			return false, fmt.Errorf("Fake driver is unable to find cursor field %s", c.DriverName)
+		}
+
+		cmp, err := compareValues(f.Value, c.Value)
+		if err != nil {
+			return false, err
+		}
+
+		if c.Descending {
+			cmp = -cmp
+		}
+
+		if cmp != 0 {
+			return cmp > 0, nil
+		}
+	}
+
+	return false, nil
+}
+
+func sortRecords(records [][]field.Field, ctx context.Context) error {
+	orderings, err := orderingsFor(ctx.GetOrder())
+	if err != nil {
+		return err
+	}
+	orderings = append(orderings, ctx.GetOrderBy()...)
+
+	if len(orderings) == 0 {
+		return nil
+	}
+
+	var sortErr error
+	sort.SliceStable(records, func(i, j int) bool {
+		for _, o := range orderings {
+			l, lok := findField(records[i], o.Column)
+			r, rok := findField(records[j], o.Column)
+			if !lok || !rok {
+				sortErr = fmt.Errorf("Fake driver is unable to order by unknown field %s", o.Column)
+				return false
+			}
+
+			lnull, rnull := isNull(l.Value), isNull(r.Value)
+			if lnull || rnull {
+				if lnull == rnull {
+					continue
+				}
+
+				nullsFirst := o.Nulls == context.NullsFirst ||
+					(o.Nulls == context.NullsDefault && o.Descending)
+				return lnull == nullsFirst
+			}
+
+			cmp, err := compareValues(plainValue(l.Value), plainValue(r.Value))
+			if err != nil {
+				sortErr = err
+				return false
+			}
+
+			if o.Descending {
+				cmp = -cmp
+			}
+
+			if cmp != 0 {
+				return cmp < 0
+			}
+		}
+		return false
+	})
+
+	return sortErr
+}
+
+// orderingsFor parses raw order, that is required to be a list of plain
+// columns with optional ASC or DESC
+func orderingsFor(order string) ([]context.Ordering, error) {
+	orderings := []context.Ordering{}
+	if strings.TrimSpace(order) == "" {
+		return orderings, nil
+	}
+
+	for _, part := range strings.Split(order, ",") {
+		words := strings.Fields(part)
+		if len(words) == 0 || len(words) > 2 {
+			return nil, fmt.Errorf("Fake driver supports only ordering by plain columns, but got: '%s'", order)
+		}
+
+		o := context.Ordering{Column: words[0]}
+		if len(words) == 2 {
+			switch strings.ToUpper(words[1]) {
+			case "ASC":
+			case "DESC":
+				o.Descending = true
+			default:
+				return nil, fmt.Errorf("Fake driver supports only ordering by plain columns, but got: '%s'", order)
+			}
+		}
+
+		orderings = append(orderings, o)
+	}
+
+	return orderings, nil
+}
+
+func findField(record []field.Field, driverName string) (field.Field, bool) {
+	for _, f := range record {
+		if f.DriverName == driverName {
+			return f, true
+		}
+	}
+	return field.Field{}, false
+}
+
+func compareValues(l, r interface{}) (int, error) {
+	if lt, ok := l.(time.Time); ok {
+		if rt, ok := r.(time.Time); ok {
+			switch {
+			case lt.Before(rt):
+				return -1, nil
+			case lt.After(rt):
+				return 1, nil
+			}
+			return 0, nil
+		}
+	}
+
+	lv := reflect.ValueOf(l)
+	rv := reflect.ValueOf(r)
+
+	switch {
+	case isInt(lv) && isInt(rv):
+		switch {
+		case lv.Int() < rv.Int():
+			return -1, nil
+		case lv.Int() > rv.Int():
+			return 1, nil
+		}
+		return 0, nil
+	case isNumber(lv) && isNumber(rv):
+		return compareFloats(toFloat(lv), toFloat(rv)), nil
+	case lv.Kind() == reflect.String && rv.Kind() == reflect.String:
+		return strings.Compare(lv.String(), rv.String()), nil
+	case lv.Kind() == reflect.Bool && rv.Kind() == reflect.Bool:
+		return compareFloats(toFloat(lv), toFloat(rv)), nil
+	}
+
+	return 0, fmt.Errorf("Fake driver is unable to compare %#v with %#v", l, r)
+}
+
+func isInt(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return true
+	}
+	return false
+}
+
+func isNumber(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return true
+	}
+	return isInt(v)
+}
+
+func toFloat(v reflect.Value) float64 {
+	switch v.Kind() {
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return float64(v.Uint())
+	case reflect.Float32, reflect.Float64:
+		return v.Float()
+	case reflect.Bool:
+		if v.Bool() {
+			return 1
+		}
+		return 0
+	}
+	return float64(v.Int())
+}
+
+func compareFloats(l, r float64) int {
+	switch {
+	case l < r:
+		return -1
+	case l > r:
+		return 1
+	}
+	return 0
+}
+
 func hasField(record []field.Field, x field.Field) bool {
 	for _, f := range record {
 		if x == f {
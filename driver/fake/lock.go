@@ -0,0 +1,73 @@
+package fake
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/waterlink/rebecca/context"
+)
+
+// lockTable is for serialising conflicting row locks of fake transactions,
+// that are shared between the driver and all of its transactions
+type lockTable struct {
+	mux   *sync.Mutex
+	cond  *sync.Cond
+	locks map[string]map[*Driver]bool
+}
+
+func newLockTable() *lockTable {
+	mux := &sync.Mutex{}
+	return &lockTable{
+		mux:   mux,
+		cond:  sync.NewCond(mux),
+		locks: map[string]map[*Driver]bool{},
+	}
+}
+
+// acquire locks the key for the owner, it returns false when the key is
+// locked by another owner and lock skips locked rows
+func (l *lockTable) acquire(owner *Driver, key string, lock context.Lock) (bool, error) {
+	l.mux.Lock()
+	defer l.mux.Unlock()
+
+	exclusive := lock.Strength == context.ForUpdate
+	for !l.available(owner, key, exclusive) {
+		switch lock.Wait {
+		case context.NoWait:
+			return false, fmt.Errorf("Fake driver is unable to obtain lock %s on %s", lock.Strength, key)
+		case context.SkipLocked:
+			return false, nil
+		}
+
+		l.cond.Wait()
+	}
+
+	if l.locks[key] == nil {
+		l.locks[key] = map[*Driver]bool{}
+	}
+	l.locks[key][owner] = l.locks[key][owner] || exclusive
+	return true, nil
+}
+
+func (l *lockTable) available(owner *Driver, key string, exclusive bool) bool {
+	for other, otherExclusive := range l.locks[key] {
+		if other != owner && (exclusive || otherExclusive) {
+			return false
+		}
+	}
+	return true
+}
+
+// release releases all locks of the owner
+func (l *lockTable) release(owner *Driver) {
+	l.mux.Lock()
+	defer l.mux.Unlock()
+
+	for key, owners := range l.locks {
+		delete(owners, owner)
+		if len(owners) == 0 {
+			delete(l.locks, key)
+		}
+	}
+	l.cond.Broadcast()
+}
@@ -0,0 +1,110 @@
+package driver
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// QuerySegment is for representing part of the query, that is either code,
+// or string literal, quoted identifier or comment
+type QuerySegment struct {
+	Text string
+	Code bool
+}
+
+// SplitQuery is for splitting query into segments of code and segments of
+// string literals, quoted identifiers and comments, so that placeholders are
+// looked for only in code
+func SplitQuery(query string) []QuerySegment {
+	segments := []QuerySegment{}
+	start := 0
+	code := true
+
+	flush := func(end int, nextCode bool) {
+		if end > start {
+			segments = append(segments, QuerySegment{Text: query[start:end], Code: code})
+		}
+		start = end
+		code = nextCode
+	}
+
+	for i := 0; i < len(query); i++ {
+		var end int
+		switch {
+		case query[i] == '\'' || query[i] == '"':
+			end = closingQuote(query, i+1, query[i])
+		case strings.HasPrefix(query[i:], "--"):
+			end = closingIndex(query, i+2, "\n")
+		case strings.HasPrefix(query[i:], "/*"):
+			end = closingIndex(query, i+2, "*/")
+		case query[i] == '$':
+			tag := dollarQuoteTag.FindString(query[i:])
+			if tag == "" {
+				continue
+			}
+			end = closingIndex(query, i+len(tag), tag)
+		default:
+			continue
+		}
+
+		flush(i, false)
+		flush(end, true)
+		i = end - 1
+	}
+
+	flush(len(query), true)
+	return segments
+}
+
+var dollarQuoteTag = regexp.MustCompile(`^\$([A-Za-z_][A-Za-z0-9_]*)?\$`)
+
+func closingQuote(query string, from int, quote byte) int {
+	for i := from; i < len(query); i++ {
+		if query[i] != quote {
+			continue
+		}
+
+		if i+1 < len(query) && query[i+1] == quote {
+			i++
+			continue
+		}
+
+		return i + 1
+	}
+	return len(query)
+}
+
+func closingIndex(query string, from int, closing string) int {
+	if from > len(query) {
+		return len(query)
+	}
+
+	i := strings.Index(query[from:], closing)
+	if i < 0 {
+		return len(query)
+	}
+	return from + i + len(closing)
+}
+
+var dollarPlaceholder = regexp.MustCompile(`\$([0-9]+)`)
+
+// ShiftPlaceholders is for renumbering $n placeholders of the query to
+// $(n+offset), so that it can be combined with another query, that has offset
+// arguments already
+func ShiftPlaceholders(query string, offset int) string {
+	result := ""
+	for _, segment := range SplitQuery(query) {
+		if !segment.Code {
+			result += segment.Text
+			continue
+		}
+
+		result += dollarPlaceholder.ReplaceAllStringFunc(segment.Text, func(match string) string {
+			n, _ := strconv.Atoi(match[1:])
+			return fmt.Sprintf("$%d", n+offset)
+		})
+	}
+	return result
+}
@@ -116,9 +116,145 @@ func ExampleWhere() {
 	fmt.Print(teenagers)
 }
 
+func ExampleFindInBatches() {
+	type Person struct {
+		// ...
+	}
+
+	err := rebecca.FindInBatches(&[]Person{}, 1000, func(batch []Person) error {
+		// At this point batch contains up to 1000 Person records, batches come
+		// in order of primary key.
+		fmt.Print(batch)
+		return nil
+	})
+	if err != nil {
+		panic(err)
+	}
+}
+
+func ExampleCount() {
+	type Person struct {
+		// ...
+	}
+
+	count, err := rebecca.Count(&Person{}, "age < $1", 21)
+	if err != nil {
+		panic(err)
+	}
+	// At this point count contains amount of Person records with age < 21.
+	fmt.Print(count)
+}
+
+func ExamplePaginate() {
+	type Person struct {
+		// ...
+	}
+
+	teenagers := []Person{}
+	pagination, err := rebecca.Paginate(&teenagers, 3, 50, "age < $1", 21)
+	if err != nil {
+		panic(err)
+	}
+	// At this point teenagers contains third page of 50 Person records with
+	// age < 21 and pagination contains total count of such records and
+	// amount of pages.
+	fmt.Print(teenagers, pagination.Total, pagination.Pages)
+}
+
+func ExampleWhere_conditions() {
+	type Person struct {
+		// ...
+	}
+
+	teenagers := []Person{}
+	cond := rebecca.And(rebecca.Gte("age", 13), rebecca.Lte("age", 19))
+	if err := rebecca.Where(&teenagers, cond); err != nil {
+		panic(err)
+	}
+
+	// At this point teenagers contains all Person records with age between 13
+	// and 19. The same query works with every driver.
+	fmt.Print(teenagers)
+}
+
+func ExampleWhere_named() {
+	type Person struct {
+		// ...
+	}
+
+	teenagers := []Person{}
+	params := rebecca.Named{"min": 13, "max": 19}
+	if err := rebecca.Where(&teenagers, "age >= :min AND age <= :max", params); err != nil {
+		panic(err)
+	}
+
+	// At this point teenagers contains all Person records with age between 13
+	// and 19.
+	fmt.Print(teenagers)
+}
+
+func ExampleWhereExample() {
+	type Person struct {
+		rebecca.ModelMetadata `tablename:"people"`
+
+		ID   int    `rebecca:"id" rebecca_primary:"true"`
+		Name string `rebecca:"name"`
+		Age  int    `rebecca:"age"`
+	}
+
+	johns := []Person{}
+	if err := rebecca.WhereExample(&johns, &Person{Name: "John"}); err != nil {
+		panic(err)
+	}
+
+	// At this point johns contains all Person records with name="John".
+	fmt.Print(johns)
+
+	newbornJohns := []Person{}
+	if err := rebecca.WhereExample(&newbornJohns, &Person{Name: "John"}, "Age"); err != nil {
+		panic(err)
+	}
+
+	// At this point newbornJohns contains all Person records with name="John"
+	// and age=0.
+	fmt.Print(newbornJohns)
+}
+
 func ExampleExec() {
 	ID := 25
 	if err := rebecca.Exec("UPDATE counters SET value = value + 1 WHERE id = $1", ID); err != nil {
 		panic(err)
 	}
 }
+
+func ExampleQuery() {
+	type Person struct {
+		rebecca.ModelMetadata `tablename:"people"`
+
+		// ...
+	}
+
+	adults, err := rebecca.Query[Person]().Where(rebecca.Gte("age", 18)).Order("age DESC").Limit(20).All()
+	if err != nil {
+		panic(err)
+	}
+	// At this point adults is of type []Person and contains 20 oldest Person
+	// records.
+	fmt.Print(adults)
+}
+
+func ExampleRepository() {
+	type Person struct {
+		rebecca.ModelMetadata `tablename:"people"`
+
+		// ...
+	}
+
+	people := rebecca.Repository[Person]{}
+	person, err := people.Get(25)
+	if err != nil {
+		panic(err)
+	}
+	// At this point person is of type *Person.
+	fmt.Print(person)
+}
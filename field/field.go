@@ -7,6 +7,8 @@ type Field struct {
 	Name       string
 	DriverName string
 	Primary    bool
+	Expression bool // DriverName is an expression, not a column
+	Tenant     bool // Value identifies tenant, the record belongs to
 	Ty         reflect.Type
 	Value      interface{}
 }
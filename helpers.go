@@ -3,26 +3,59 @@ package rebecca
 // This file contains shared functions for rebecca package.
 
 import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"database/sql"
+	sqldriver "database/sql/driver"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"reflect"
-
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"unicode"
+
+	"github.com/waterlink/rebecca/condition"
+	"github.com/waterlink/rebecca/context"
 	"github.com/waterlink/rebecca/driver"
 	"github.com/waterlink/rebecca/field"
 )
 
-func get(tx interface{}, ID interface{}, record interface{}) error {
-	d, lock := driver.Get()
-	defer lock.Unlock()
+func get(ctx *Context, ID interface{}, record interface{}) error {
+	meta, err := metadataFor(ctx, record)
+	if err != nil {
+		return err
+	}
 
-	meta, err := getMetadata(record)
+	if meta.tenant.Tenant || !ctx.unscoped && hasDefaultScopes(&meta) {
+		if err := ctx.First(record, Eq(meta.primary.DriverName, ID)); err != nil {
+			return fmt.Errorf("Unable to find record - %s", err)
+		}
+		return nil
+	}
+
+	if err := getByPrimary(ctx, &meta, ID, record); err != nil {
+		return err
+	}
+
+	return ctx.preload(record)
+}
+
+func getByPrimary(ctx *Context, meta *metadata, ID interface{}, record interface{}) error {
+	d, lock, err := driverFor(ctx)
 	if err != nil {
 		return err
 	}
+	defer lock.Unlock()
 
 	idField := meta.primary
 	idField.Value = ID
 
-	fields, err := d.Get(tx, meta.tablename, meta.fields, idField)
+	fields, err := d.Get(ctx.tx, meta.tablename, meta.fields, idField)
 	if err != nil {
 		return fmt.Errorf("Unable to find record - %s", err)
 	}
@@ -34,20 +67,12 @@ func get(tx interface{}, ID interface{}, record interface{}) error {
 	return nil
 }
 
-func save(tx interface{}, record interface{}) error {
-	d, lock := driver.Get()
-	defer lock.Unlock()
-
-	meta, err := getMetadata(record)
+func save(ctx *Context, record interface{}) error {
+	meta, err := metadataFor(ctx, record)
 	if err != nil {
 		return err
 	}
 
-	fields, err := fieldsFor(&meta, record)
-	if err != nil {
-		return fmt.Errorf("Unable to fetch fields for record %+v", record)
-	}
-
 	idField := meta.primary
 	if err := ensureHasID(record, idField); err != nil {
 		return err
@@ -58,8 +83,27 @@ func save(tx interface{}, record interface{}) error {
 		return fmt.Errorf("Unable to determine if record %+v is new - %s", record, err)
 	}
 
+	if err := assignBelongsTo(&meta, record); err != nil {
+		return fmt.Errorf("Unable to save record %+v - %s", record, err)
+	}
+
+	if err := ensureTenant(ctx, &meta, record); err != nil {
+		return fmt.Errorf("Unable to save record %+v - %s", record, err)
+	}
+
+	fields, err := fieldsFor(&meta, record)
+	if err != nil {
+		return fmt.Errorf("Unable to fetch fields for record %+v", record)
+	}
+
+	d, lock, err := driverFor(ctx)
+	if err != nil {
+		return err
+	}
+	defer lock.Unlock()
+
 	if isNew {
-		if err := d.Create(tx, meta.tablename, fields, &idField); err != nil {
+		if err := d.Create(ctx.tx, meta.tablename, fields, &idField); err != nil {
 			return fmt.Errorf("Unable to create record %+v - %s", record, err)
 		}
 
@@ -71,19 +115,20 @@ func save(tx interface{}, record interface{}) error {
 			return fmt.Errorf("Unable to fetch primary field from record %+v - %s", record, err)
 		}
 
-		if err := d.Update(tx, meta.tablename, fields, idField); err != nil {
+		if err := update(ctx, d, &meta, fields, idField); err != nil {
 			return fmt.Errorf("Unable to update record %+v - %s", record, err)
 		}
 	}
 
+	if err := assignHas(&meta, record); err != nil {
+		return fmt.Errorf("Unable to assign foreign keys of associated records of %+v - %s", record, err)
+	}
+
 	return nil
 }
 
-func remove(tx interface{}, record interface{}) error {
-	d, lock := driver.Get()
-	defer lock.Unlock()
-
-	meta, err := getMetadata(record)
+func remove(ctx *Context, record interface{}) error {
+	meta, err := metadataFor(ctx, record)
 	if err != nil {
 		return err
 	}
@@ -93,238 +138,1991 @@ func remove(tx interface{}, record interface{}) error {
 		return err
 	}
 
+	if err := ensureTenant(ctx, &meta, record); err != nil {
+		return fmt.Errorf("Unable to remove record %+v - %s", record, err)
+	}
+
 	if err := populateFieldValue(record, &idField); err != nil {
 		return fmt.Errorf("Unable to populate primary field of record %+v - %s", record, err)
 	}
 
-	if err := d.Remove(tx, meta.tablename, idField); err != nil {
+	d, lock, err := driverFor(ctx)
+	if err != nil {
+		return err
+	}
+	defer lock.Unlock()
+
+	if err := removeByID(ctx, d, &meta, idField); err != nil {
 		return fmt.Errorf("Unable to remove record %+v - %s", record, err)
 	}
 
 	return nil
 }
 
-func exec(tx interface{}, query string, args ...interface{}) error {
-	d, lock := driver.Get()
-	defer lock.Unlock()
-	return d.Exec(tx, query, args...)
-}
+// ensureTenant assigns tenant of the Context to the record of tenant-scoped
+// model, unless it has one already, and refuses records of other tenants
+func ensureTenant(ctx *Context, meta *metadata, record interface{}) error {
+	if !meta.tenant.Tenant {
+		return nil
+	}
 
-func getMetadata(record interface{}) (metadata, error) {
-	meta, err := fetchMetadata(record)
+	tenant, err := tenantFor(ctx, meta)
 	if err != nil {
-		return metadata{}, fmt.Errorf(
-			"Unable to fetch record's metadata - type=%s - %s",
-			typeName(record),
-			err,
-		)
+		return err
 	}
-	return meta, nil
-}
 
-func typeHasElem(ty reflect.Type) bool {
-	return ty.Kind() == reflect.Ptr ||
-		ty.Kind() == reflect.Interface ||
-		ty.Kind() == reflect.Slice
-}
+	tenantField := meta.tenant
+	if err := populateFieldValue(record, &tenantField); err != nil {
+		return err
+	}
 
-func valueHasElem(v reflect.Value) bool {
-	return v.Kind() == reflect.Ptr ||
-		v.Kind() == reflect.Interface
-}
+	if reflect.ValueOf(tenantField.Value).IsZero() {
+		tenantField.Value = tenant
+		if err := assignField(record, tenantField); err != nil {
+			return err
+		}
+	}
 
-func typeName(record interface{}) string {
-	ty := reflect.TypeOf(record)
-	for typeHasElem(ty) {
-		ty = ty.Elem()
+	if !reflect.DeepEqual(tenantField.Value, tenant) {
+		return fmt.Errorf("it belongs to tenant %v, not to %v", tenantField.Value, tenant)
 	}
-	return ty.PkgPath() + "." + ty.Name()
-}
 
-func fetchMetadata(record interface{}) (metadata, error) {
-	missingMetadata := metadata{}
+	return nil
+}
 
-	ty := reflect.TypeOf(record)
-	for typeHasElem(ty) {
-		ty = ty.Elem()
+// update updates the record given its ID. Record of tenant-scoped model is
+// updated only when it belongs to the tenant in the database, which is
+// checked by the same query
+func update(ctx *Context, d driver.Driver, meta *metadata, fields []field.Field, idField field.Field) error {
+	if !meta.tenant.Tenant {
+		return d.Update(ctx.tx, meta.tablename, fields, idField)
 	}
 
-	if ty.Kind() != reflect.Struct {
-		return missingMetadata, fmt.Errorf("Rebecca's model is required to be struct, but got: %+v", record)
+	scoped, tenant, err := scopedFor(ctx, d, meta)
+	if err != nil {
+		return err
 	}
 
-	metaField, ok := ty.FieldByName("ModelMetadata")
-	if !ok {
-		return missingMetadata, fmt.Errorf("Rebecca's model is required to embed rebecca.ModelMetadata")
+	count, err := scoped.UpdateWhere(ctx.tx, meta.tablename, fields, idField, Eq(meta.tenant.DriverName, tenant))
+	if err != nil {
+		return err
 	}
 
-	metaType := metaField.Type
-	if metaType.PkgPath() != "github.com/waterlink/rebecca" || metaType.Name() != "ModelMetadata" {
-		return missingMetadata, fmt.Errorf("Rebecca's model is required to embed rebecca.ModelMetadata")
+	if count == 0 {
+		return fmt.Errorf("it does not belong to tenant %v", tenant)
 	}
 
-	meta := metadata{}
+	return nil
+}
 
-	metaTag := metaField.Tag
-	tablename := metaTag.Get("tablename")
-	if tablename == "" {
-		return missingMetadata, fmt.Errorf("tablename tag metadata is missing on ModelMetadata")
+// removeByID removes the record given its ID. Record of tenant-scoped model
+// is removed only when it belongs to the tenant in the database, which is
+// checked by the same query
+func removeByID(ctx *Context, d driver.Driver, meta *metadata, idField field.Field) error {
+	if !meta.tenant.Tenant {
+		return d.Remove(ctx.tx, meta.tablename, idField)
 	}
 
-	meta.tablename = tablename
-
-	fieldCount := ty.NumField()
-	for i := 0; i < fieldCount; i++ {
-		f := ty.Field(i)
-		if f.Name == "ModelMetadata" {
-			continue
-		}
-
-		metaField := field.Field{
-			Name:       f.Name,
-			Ty:         f.Type,
-			DriverName: driverName(f),
-			Primary:    isPrimary(f),
-		}
+	scoped, tenant, err := scopedFor(ctx, d, meta)
+	if err != nil {
+		return err
+	}
 
-		if metaField.Primary {
-			meta.primary = metaField
-		}
+	count, err := scoped.RemoveWhere(ctx.tx, meta.tablename, idField, Eq(meta.tenant.DriverName, tenant))
+	if err != nil {
+		return err
+	}
 
-		meta.fields = append(meta.fields, metaField)
+	if count == 0 {
+		return fmt.Errorf("it does not belong to tenant %v", tenant)
 	}
 
-	return meta, nil
+	return nil
 }
 
-func driverName(field reflect.StructField) string {
-	name := field.Tag.Get("rebecca")
-	if name == "" {
-		name = field.Name
+func scopedFor(ctx *Context, d driver.Driver, meta *metadata) (driver.ScopedDriver, interface{}, error) {
+	scoped, ok := d.(driver.ScopedDriver)
+	if !ok {
+		return nil, nil, fmt.Errorf("driver %T does not support changing records of tenant-scoped models, it should implement driver.ScopedDriver", d)
 	}
-	return name
-}
 
-func isPrimary(field reflect.StructField) bool {
-	return field.Tag.Get("rebecca_primary") == "true"
+	tenant, err := tenantFor(ctx, meta)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return scoped, tenant, nil
 }
 
-func setFields(record interface{}, fields []field.Field) error {
-	for _, f := range fields {
-		if err := assignField(record, f); err != nil {
-			return err
-		}
+// tenantFor fetches tenant of the Context converted to the type of tenant
+// field of the model
+func tenantFor(ctx *Context, meta *metadata) (interface{}, error) {
+	if ctx.tenant == nil {
+		return nil, fmt.Errorf("tenant is required for tenant-scoped model, use Context.ForTenant")
 	}
 
-	return nil
+	tenant := reflect.ValueOf(ctx.tenant)
+	if !isKeyConvertible(tenant.Type(), meta.tenant.Ty) {
+		return nil, fmt.Errorf("Unable to use tenant of type %s for field %s of type %s", tenant.Type(), meta.tenant.Name, meta.tenant.Ty)
+	}
+
+	return tenant.Convert(meta.tenant.Ty).Interface(), nil
 }
 
-func assignField(record interface{}, f field.Field) error {
-	v := reflect.ValueOf(record)
-	for valueHasElem(v) {
-		v = v.Elem()
+// driverFor fetches the driver and routes queries within Context of the
+// tenant to the driver of the tenant, when the driver supports it. Returned
+// lock is required to be unlocked after use. Queries, that lock rows, can
+// wait for other transactions to finish, which take the driver lock to
+// commit, so the driver lock is released right away for them. It is safe,
+// because such queries run within transaction, that keeps working with the
+// driver it was started with, even when the active driver is replaced
+func driverFor(ctx *Context) (driver.Driver, sync.Locker, error) {
+	d, lock := driver.Get()
+	if !ctx.Lock.IsZero() {
+		lock.Unlock()
+		lock = noLock{}
 	}
 
-	if _, ok := v.Type().FieldByName(f.Name); !ok {
-		return fmt.Errorf("Field %s not found on record %+v", f.Name, record)
+	if ctx.tenant == nil {
+		return d, lock, nil
 	}
 
-	vf := v.FieldByName(f.Name)
-	if !vf.CanSet() {
-		return fmt.Errorf(
-			"Unable to set field %s on record %+v. It is required to be exported and addressable",
-			f.Name,
-			record,
-		)
+	tenantDriver, ok := d.(driver.TenantDriver)
+	if !ok {
+		return d, lock, nil
 	}
 
-	vf.Set(reflect.ValueOf(f.Value))
+	routed, err := tenantDriver.ForTenant(ctx.tenant)
+	if err != nil {
+		lock.Unlock()
+		return nil, nil, fmt.Errorf("Unable to route queries to tenant %v - %s", ctx.tenant, err)
+	}
 
-	return nil
+	return routed, lock, nil
 }
 
-func fieldsFor(meta *metadata, record interface{}) ([]field.Field, error) {
-	v := reflect.ValueOf(record)
-	for valueHasElem(v) {
-		v = v.Elem()
+type noLock struct{}
+
+func (noLock) Lock()   {}
+func (noLock) Unlock() {}
+
+func contextOrDefault(ctx *Context) *Context {
+	if ctx == nil {
+		return &Context{}
 	}
+	return ctx
+}
 
-	ty := v.Type()
+func exec(tx interface{}, query string, args ...interface{}) error {
+	d, lock := driver.Get()
+	defer lock.Unlock()
 
-	fields := []field.Field{}
-	for _, f := range meta.fields {
-		if _, ok := ty.FieldByName(f.Name); !ok {
-			return nil, fmt.Errorf("Field %s not found on record %+v", f.Name, record)
-		}
+	query, args, err := prepareQuery(query, args, d.PlaceholderStyle())
+	if err != nil {
+		return err
+	}
 
-		itsField := f
-		itsField.Value = v.FieldByName(f.Name).Interface()
-		fields = append(fields, itsField)
+	return d.Exec(tx, query, args...)
+}
+
+// selectedFields filters fields by their names or driver names, all fields
+// are selected when names are empty
+func selectedFields(fields []field.Field, names []string) ([]field.Field, error) {
+	if len(names) == 0 {
+		return fields, nil
 	}
 
-	return fields, nil
+	selected := []field.Field{}
+	for _, name := range names {
+		f, ok := fieldByName(fields, name)
+		if !ok {
+			return nil, fmt.Errorf("Unable to select unknown field %s", name)
+		}
+		selected = append(selected, f)
+	}
+
+	return selected, nil
 }
 
-func populateFieldValue(record interface{}, f *field.Field) error {
-	v := reflect.ValueOf(record)
-	for valueHasElem(v) {
-		v = v.Elem()
+// withSelected adds required fields to the selection, unless they are
+// selected already or all fields are selected. It is used for fields, that
+// are needed to build map keys and page tokens
+func withSelected(names []string, required ...field.Field) []string {
+	if len(names) == 0 {
+		return names
 	}
 
-	ty := v.Type()
+	result := append([]string{}, names...)
+	for _, f := range required {
+		selected := false
+		for _, name := range names {
+			selected = selected || name == f.Name || name == f.DriverName
+		}
 
-	if _, ok := ty.FieldByName(f.Name); !ok {
-		return fmt.Errorf("Field %s not found on record %+v", f.Name, record)
+		if !selected {
+			result = append(result, f.Name)
+		}
 	}
 
-	f.Value = v.FieldByName(f.Name).Interface()
-	return nil
+	return result
 }
 
-func isNewRecord(record interface{}, ID field.Field) (bool, error) {
-	v := reflect.ValueOf(record)
-	for valueHasElem(v) {
-		v = v.Elem()
+func fieldsOfCursor(cursor context.Cursor) []field.Field {
+	fields := []field.Field{}
+	for _, f := range cursor {
+		fields = append(fields, f.Field)
 	}
+	return fields
+}
 
-	ty := v.Type()
+// selectedFor is for fields selected for given records, map records require
+// the primary key for their keys
+func selectedFor(meta *metadata, names []string, records interface{}) ([]field.Field, error) {
+	target := reflect.ValueOf(records)
+	if target.Kind() == reflect.Ptr {
+		target = target.Elem()
+	}
 
-	if _, ok := ty.FieldByName(ID.Name); !ok {
-		return false, fmt.Errorf("Field %s not found on record %+v", ID.Name, record)
+	if target.Kind() == reflect.Map {
+		names = withSelected(names, meta.primary)
 	}
 
-	f := v.FieldByName(ID.Name)
-	return f.Interface() == reflect.Zero(f.Type()).Interface(), nil
+	return selectedFields(meta.fields, names)
 }
 
-func zeroValueOf(value interface{}) interface{} {
-	ty := reflect.TypeOf(value)
-	for typeHasElem(ty) {
-		ty = ty.Elem()
+// scopesFor resolves names of scopes defined for the model with
+// DefineScope, and adds default scopes of the model, unless unscoped
+func scopesFor(meta *metadata, names []string, unscoped bool) (condition.Condition, error) {
+	definedScopesMux.RLock()
+	defer definedScopesMux.RUnlock()
+
+	conditions := []condition.Condition{}
+	if !unscoped {
+		conditions = append(conditions, defaultScopes[meta.ty]...)
 	}
 
-	return reflect.New(ty).Interface()
+	for _, name := range names {
+		cond, ok := definedScopes[meta.ty][name]
+		if !ok {
+			return nil, fmt.Errorf("Unable to find scope %s defined for %s", name, meta.modelTablename)
+		}
+		conditions = append(conditions, cond)
+	}
+
+	return andConditions(conditions...), nil
 }
 
-func populateRecordsFromFieldss(records interface{}, fieldss [][]field.Field) error {
-	for _, fields := range fieldss {
-		record := zeroValueOf(records)
-		if err := setFields(&record, fields); err != nil {
-			return fmt.Errorf("Unable to assign fields for new record - %s", err)
+func hasDefaultScopes(meta *metadata) bool {
+	definedScopesMux.RLock()
+	defer definedScopesMux.RUnlock()
+
+	return len(defaultScopes[meta.ty]) > 0
+}
+
+// joinsFor resolves names of joins defined for the table with DefineJoin,
+// raw join clauses are kept as is
+func joinsFor(tablename string, joins []string) ([]string, error) {
+	definedJoinsMux.RLock()
+	defer definedJoinsMux.RUnlock()
+
+	resolved := []string{}
+	for _, join := range joins {
+		if clause, ok := definedJoins[tablename][join]; ok {
+			resolved = append(resolved, clause)
+			continue
+		}
+
+		if !strings.ContainsAny(join, " \t\n") {
+			return nil, fmt.Errorf("Unable to find join %s defined for %s", join, tablename)
 		}
-		v := reflect.ValueOf(records).Elem()
-		v.Set(reflect.Append(v, reflect.ValueOf(record).Elem()))
+
+		resolved = append(resolved, join)
 	}
 
-	return nil
+	return resolved, nil
 }
 
-func ensureHasID(record interface{}, idField field.Field) error {
-	if !idField.Primary {
-		return fmt.Errorf(
-			"Record has no primary field - type=%s - Use `rebecca_primary:\"true\"` annotation",
-			typeName(record),
-		)
+func getMetadata(record interface{}) (metadata, error) {
+	ty := modelType(record)
+	if meta, ok := cachedMetadata(ty); ok {
+		return meta, nil
 	}
+
+	meta, err := fetchMetadata(record)
+	if err != nil {
+		return metadata{}, fmt.Errorf(
+			"Unable to fetch record's metadata - type=%s - %s",
+			typeName(record),
+			err,
+		)
+	}
+
+	meta.ty = ty
+	cacheMetadata(ty, meta)
+	return meta, nil
+}
+
+func modelType(record interface{}) reflect.Type {
+	ty := reflect.TypeOf(record)
+	for ty != nil && typeHasElem(ty) {
+		ty = ty.Elem()
+	}
+	return ty
+}
+
+// cachedMetadata fetches metadata of the model type from the cache. Fields
+// are copied, so that callers are free to modify them
+func cachedMetadata(ty reflect.Type) (metadata, bool) {
+	metadataCacheMux.RLock()
+	defer metadataCacheMux.RUnlock()
+
+	meta, ok := metadataCache[ty]
+	if !ok {
+		return metadata{}, false
+	}
+
+	meta.fields = append([]field.Field{}, meta.fields...)
+	return meta, true
+}
+
+func cacheMetadata(ty reflect.Type, meta metadata) {
+	if ty == nil {
+		return
+	}
+
+	metadataCacheMux.Lock()
+	defer metadataCacheMux.Unlock()
+
+	metadataCache[ty] = meta
+}
+
+func uncacheAllMetadata() {
+	metadataCacheMux.Lock()
+	defer metadataCacheMux.Unlock()
+
+	metadataCache = map[reflect.Type]metadata{}
+}
+
+func uncacheMetadata(ty reflect.Type) {
+	metadataCacheMux.Lock()
+	defer metadataCacheMux.Unlock()
+
+	delete(metadataCache, ty)
+}
+
+// validateMetadata reports all problems of the model metadata, that would
+// otherwise surface only when the model is used
+func validateMetadata(ty reflect.Type, meta metadata) []string {
+	problems := []string{}
+
+	primaries := 0
+	driverNames := map[string]bool{}
+	for _, f := range meta.fields {
+		if f.Primary {
+			primaries++
+		}
+
+		if driverNames[f.DriverName] {
+			problems = append(problems, fmt.Sprintf("column %s is duplicated", f.DriverName))
+		}
+		driverNames[f.DriverName] = true
+
+		if structField, ok := ty.FieldByName(f.Name); ok && structField.PkgPath != "" {
+			problems = append(problems, fmt.Sprintf("field %s is unexported", f.Name))
+		}
+	}
+
+	if primaries == 0 {
+		problems = append(problems, "primary field is missing")
+	}
+
+	if primaries > 1 {
+		problems = append(problems, fmt.Sprintf("primary field is required to be only one, but got: %d", primaries))
+	}
+
+	return problems
+}
+
+// metadataFor fetches metadata of the record, tablename of which is
+// overridden, when the model implements ContextTableNamer or TableNamer
+func metadataFor(ctx *Context, record interface{}) (metadata, error) {
+	meta, err := getMetadata(record)
+	if err != nil {
+		return metadata{}, err
+	}
+
+	var tablename string
+	switch model := modelFor(record).(type) {
+	case ContextTableNamer:
+		tablename = model.TableNameFor(ctx, record)
+	case TableNamer:
+		tablename = model.TableName()
+	default:
+		return meta, nil
+	}
+
+	if tablename == "" {
+		return metadata{}, fmt.Errorf(
+			"Unable to fetch record's metadata - type=%s - dynamic table name is empty",
+			typeName(record),
+		)
+	}
+
+	if meta.schema != "" && !strings.Contains(tablename, ".") {
+		tablename = meta.schema + "." + tablename
+	}
+
+	meta.tablename = tablename
+	return meta, nil
+}
+
+// modelFor returns the record itself, when it is a pointer to struct, or a
+// pointer to zero value of the model otherwise, so that methods of the model
+// can be called on it
+func modelFor(record interface{}) interface{} {
+	v := reflect.ValueOf(record)
+	for valueHasElem(v) && !v.IsNil() && valueHasElem(v.Elem()) {
+		v = v.Elem()
+	}
+
+	if v.Kind() == reflect.Ptr && !v.IsNil() && v.Elem().Kind() == reflect.Struct {
+		return v.Interface()
+	}
+
+	if v.Kind() == reflect.Struct {
+		model := reflect.New(v.Type())
+		model.Elem().Set(v)
+		return model.Interface()
+	}
+
+	ty := reflect.TypeOf(record)
+	for typeHasElem(ty) {
+		ty = ty.Elem()
+	}
+	return reflect.New(ty).Interface()
+}
+
+var (
+	tableNamerType        = reflect.TypeOf((*TableNamer)(nil)).Elem()
+	contextTableNamerType = reflect.TypeOf((*ContextTableNamer)(nil)).Elem()
+)
+
+func hasDynamicTablename(ty reflect.Type) bool {
+	ptr := reflect.PtrTo(ty)
+	return ptr.Implements(tableNamerType) || ptr.Implements(contextTableNamerType)
+}
+
+func typeHasElem(ty reflect.Type) bool {
+	return ty.Kind() == reflect.Ptr ||
+		ty.Kind() == reflect.Interface ||
+		ty.Kind() == reflect.Slice ||
+		ty.Kind() == reflect.Map
+}
+
+func valueHasElem(v reflect.Value) bool {
+	return v.Kind() == reflect.Ptr ||
+		v.Kind() == reflect.Interface
+}
+
+func typeName(record interface{}) string {
+	ty := reflect.TypeOf(record)
+	for typeHasElem(ty) {
+		ty = ty.Elem()
+	}
+	return ty.PkgPath() + "." + ty.Name()
+}
+
+func fetchMetadata(record interface{}) (metadata, error) {
+	meta, problems, err := inspectMetadata(record)
+	if err != nil {
+		return metadata{}, err
+	}
+
+	if len(problems) > 0 {
+		return metadata{}, errors.New(strings.Join(problems, "; "))
+	}
+
+	return meta, nil
+}
+
+// inspectMetadata builds metadata of the record. It fails only when the
+// record is not a model at all, and otherwise it collects every problem of
+// the model, so that all of them can be reported at once
+func inspectMetadata(record interface{}) (metadata, []string, error) {
+	missingMetadata := metadata{}
+	problems := []string{}
+
+	ty := reflect.TypeOf(record)
+	for typeHasElem(ty) {
+		ty = ty.Elem()
+	}
+
+	if ty.Kind() != reflect.Struct {
+		return missingMetadata, nil, fmt.Errorf("Rebecca's model is required to be struct, but got: %+v", record)
+	}
+
+	if meta, ok := registeredMetadata(ty); ok {
+		return meta, problems, nil
+	}
+
+	metaField, ok := ty.FieldByName("ModelMetadata")
+	if !ok {
+		return missingMetadata, nil, fmt.Errorf("Rebecca's model is required to embed rebecca.ModelMetadata")
+	}
+
+	metaType := metaField.Type
+	if metaType.PkgPath() != "github.com/waterlink/rebecca" || metaType.Name() != "ModelMetadata" {
+		return missingMetadata, nil, fmt.Errorf("Rebecca's model is required to embed rebecca.ModelMetadata")
+	}
+
+	meta := metadata{}
+
+	naming := namingStrategyFor(ty)
+
+	metaTag := metaField.Tag
+	tablename := metaTag.Get("tablename")
+	if tablename == "" && naming.Table != nil {
+		tablename = naming.Table(ty.Name())
+	}
+
+	if tablename == "" && !hasDynamicTablename(ty) {
+		problems = append(problems, "tablename tag metadata is missing on ModelMetadata")
+	}
+
+	meta.schema = metaTag.Get("schema")
+	if tablename != "" && meta.schema != "" {
+		tablename = meta.schema + "." + tablename
+	}
+
+	meta.tablename = tablename
+	meta.modelTablename = tablename
+	if meta.modelTablename == "" {
+		meta.modelTablename = ty.PkgPath() + "." + ty.Name()
+	}
+
+	fieldCount := ty.NumField()
+	for i := 0; i < fieldCount; i++ {
+		f := ty.Field(i)
+		if f.Name == "ModelMetadata" {
+			continue
+		}
+
+		if assoc, ok := associationOf(ty, f); ok {
+			meta.associations = append(meta.associations, assoc)
+			continue
+		}
+
+		metaField := field.Field{
+			Name:       f.Name,
+			Ty:         f.Type,
+			DriverName: driverName(f, naming),
+			Primary:    isPrimary(f),
+			Expression: isExpression(f),
+			Tenant:     isTenant(f),
+		}
+
+		if metaField.Primary {
+			meta.primary = metaField
+		}
+
+		if metaField.Tenant {
+			meta.tenant = metaField
+		}
+
+		meta.fields = append(meta.fields, metaField)
+	}
+
+	for _, assoc := range meta.associations {
+		if assoc.kind != belongsTo || !assoc.bare {
+			continue
+		}
+
+		if _, ok := fieldByName(meta.fields, assoc.foreignKey); !ok {
+			problems = append(problems, unknownOption(assoc, meta.modelTablename).Error())
+		}
+	}
+
+	return meta, problems, nil
+}
+
+func registeredMetadata(ty reflect.Type) (metadata, bool) {
+	registeredModelsMux.RLock()
+	defer registeredModelsMux.RUnlock()
+
+	meta, ok := registeredModels[ty]
+	if !ok {
+		return metadata{}, false
+	}
+
+	meta.fields = append([]field.Field{}, meta.fields...)
+	return meta, true
+}
+
+func driverName(field reflect.StructField, naming NamingStrategy) string {
+	name := field.Tag.Get("rebecca")
+	if name == "" && naming.Column == nil {
+		name = identityName(field.Name)
+	}
+	if name == "" {
+		name = naming.Column(field.Name)
+	}
+	return name
+}
+
+func namingStrategyFor(ty reflect.Type) NamingStrategy {
+	namingStrategyMux.RLock()
+	defer namingStrategyMux.RUnlock()
+
+	if strategy, ok := modelNamingStrategy[ty]; ok {
+		return strategy
+	}
+	return namingStrategy
+}
+
+func identityName(name string) string {
+	return name
+}
+
+// nameWords splits name of Go identifier into words, keeping acronyms
+// together, for example, UserID into User and ID
+func nameWords(name string) []string {
+	words := []string{}
+	runes := []rune(name)
+	start := 0
+	for i := 1; i < len(runes); i++ {
+		prev, cur := runes[i-1], runes[i]
+		next := rune(0)
+		if i+1 < len(runes) {
+			next = runes[i+1]
+		}
+
+		lowerToUpper := unicode.IsUpper(cur) && !unicode.IsUpper(prev) && prev != '_'
+		acronymEnd := unicode.IsUpper(cur) && unicode.IsUpper(prev) && unicode.IsLower(next)
+		if cur == '_' || lowerToUpper || acronymEnd {
+			words = append(words, string(runes[start:i]))
+			start = i
+		}
+
+		if cur == '_' {
+			start = i + 1
+		}
+	}
+
+	words = append(words, string(runes[start:]))
+
+	nonEmpty := []string{}
+	for _, word := range words {
+		if word != "" {
+			nonEmpty = append(nonEmpty, word)
+		}
+	}
+	return nonEmpty
+}
+
+func snakeCase(name string) string {
+	return strings.ToLower(strings.Join(nameWords(name), "_"))
+}
+
+func camelCase(name string) string {
+	words := nameWords(name)
+	if len(words) == 0 {
+		return name
+	}
+
+	words[0] = strings.ToLower(words[0])
+	return strings.Join(words, "")
+}
+
+func pluralSnakeCase(name string) string {
+	words := strings.Split(snakeCase(name), "_")
+	words[len(words)-1] = plural(words[len(words)-1])
+	return strings.Join(words, "_")
+}
+
+var irregularPlurals = map[string]string{
+	"person": "people",
+	"man":    "men",
+	"woman":  "women",
+	"child":  "children",
+	"mouse":  "mice",
+}
+
+func plural(word string) string {
+	if irregular, ok := irregularPlurals[word]; ok {
+		return irregular
+	}
+
+	for _, suffix := range []string{"s", "x", "z", "ch", "sh"} {
+		if strings.HasSuffix(word, suffix) {
+			return word + "es"
+		}
+	}
+
+	if len(word) > 1 && strings.HasSuffix(word, "y") && !strings.ContainsAny(word[len(word)-2:len(word)-1], "aeiou") {
+		return word[:len(word)-1] + "ies"
+	}
+
+	return word + "s"
+}
+
+func isPrimary(field reflect.StructField) bool {
+	return field.Tag.Get("rebecca_primary") == "true"
+}
+
+func isExpression(field reflect.StructField) bool {
+	return field.Tag.Get("rebecca_expression") == "true"
+}
+
+// associationOf parses association tag of the field. Tag value is a list of
+// options: foreign_key=column, or bare foreign key column, or the name of
+// the field itself, which is ignored. Bare foreign key is verified, when the
+// association is resolved, see unknownOption
+func associationOf(owner reflect.Type, f reflect.StructField) (association, bool) {
+	for _, kind := range []string{belongsTo, hasOne, hasMany} {
+		tag, ok := f.Tag.Lookup("rebecca_" + kind)
+		if !ok {
+			continue
+		}
+
+		assoc := association{name: f.Name, kind: kind, ty: f.Type, owner: owner.Name()}
+		for _, option := range strings.Split(tag, ",") {
+			option = strings.TrimSpace(option)
+			switch {
+			case strings.HasPrefix(option, "foreign_key="):
+				assoc.foreignKey = strings.TrimPrefix(option, "foreign_key=")
+			case option != "" && option != f.Name:
+				assoc.foreignKey = option
+				assoc.bare = true
+			}
+		}
+
+		return assoc, true
+	}
+
+	return association{}, false
+}
+
+func isTenant(field reflect.StructField) bool {
+	return field.Tag.Get("rebecca_tenant") == "true"
+}
+
+func setFields(record interface{}, fields []field.Field) error {
+	for _, f := range fields {
+		if err := assignField(record, f); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func assignField(record interface{}, f field.Field) error {
+	v := reflect.ValueOf(record)
+	for valueHasElem(v) {
+		v = v.Elem()
+	}
+
+	if _, ok := v.Type().FieldByName(f.Name); !ok {
+		return fmt.Errorf("Field %s not found on record %+v", f.Name, record)
+	}
+
+	vf := v.FieldByName(f.Name)
+	if !vf.CanSet() {
+		return fmt.Errorf(
+			"Unable to set field %s on record %+v. It is required to be exported and addressable",
+			f.Name,
+			record,
+		)
+	}
+
+	vf.Set(reflect.ValueOf(f.Value))
+
+	return nil
+}
+
+func fieldsFor(meta *metadata, record interface{}) ([]field.Field, error) {
+	v := reflect.ValueOf(record)
+	for valueHasElem(v) {
+		v = v.Elem()
+	}
+
+	ty := v.Type()
+
+	fields := []field.Field{}
+	for _, f := range meta.fields {
+		if _, ok := ty.FieldByName(f.Name); !ok {
+			return nil, fmt.Errorf("Field %s not found on record %+v", f.Name, record)
+		}
+
+		itsField := f
+		itsField.Value = v.FieldByName(f.Name).Interface()
+		fields = append(fields, itsField)
+	}
+
+	return fields, nil
+}
+
+func populateFieldValue(record interface{}, f *field.Field) error {
+	v := reflect.ValueOf(record)
+	for valueHasElem(v) {
+		v = v.Elem()
+	}
+
+	ty := v.Type()
+
+	if _, ok := ty.FieldByName(f.Name); !ok {
+		return fmt.Errorf("Field %s not found on record %+v", f.Name, record)
+	}
+
+	f.Value = v.FieldByName(f.Name).Interface()
+	return nil
+}
+
+func isNewRecord(record interface{}, ID field.Field) (bool, error) {
+	v := reflect.ValueOf(record)
+	for valueHasElem(v) {
+		v = v.Elem()
+	}
+
+	ty := v.Type()
+
+	if _, ok := ty.FieldByName(ID.Name); !ok {
+		return false, fmt.Errorf("Field %s not found on record %+v", ID.Name, record)
+	}
+
+	f := v.FieldByName(ID.Name)
+	return f.Interface() == reflect.Zero(f.Type()).Interface(), nil
+}
+
+func zeroValueOf(value interface{}) interface{} {
+	ty := reflect.TypeOf(value)
+	for typeHasElem(ty) {
+		ty = ty.Elem()
+	}
+
+	return reflect.New(ty).Interface()
+}
+
+// populateRecordsFromFieldss appends records to the slice of models or of
+// pointers to models, or puts them into the map keyed by primary key
+func populateRecordsFromFieldss(meta *metadata, records interface{}, fieldss [][]field.Field) error {
+	target := reflect.ValueOf(records)
+	if target.Kind() == reflect.Ptr {
+		target = target.Elem()
+	}
+
+	switch target.Kind() {
+	case reflect.Slice:
+		if !target.CanSet() {
+			return fmt.Errorf("Records are required to be a pointer to slice, but got: %T", records)
+		}
+
+	case reflect.Map:
+		if target.IsNil() {
+			if !target.CanSet() {
+				return fmt.Errorf("Records are required to be a pointer to map or non-nil map, but got: %T", records)
+			}
+			target.Set(reflect.MakeMap(target.Type()))
+		}
+
+	default:
+		return fmt.Errorf("Records are required to be a pointer to slice or a map, but got: %T", records)
+	}
+
+	byPointer := target.Type().Elem().Kind() == reflect.Ptr
+	for _, fields := range fieldss {
+		record := zeroValueOf(records)
+		if err := setFields(record, fields); err != nil {
+			return fmt.Errorf("Unable to assign fields for new record - %s", err)
+		}
+
+		value := reflect.ValueOf(record)
+		if !byPointer {
+			value = value.Elem()
+		}
+
+		if target.Kind() == reflect.Slice {
+			target.Set(reflect.Append(target, value))
+			continue
+		}
+
+		key, err := primaryKeyFor(meta, record, target.Type().Key())
+		if err != nil {
+			return err
+		}
+		target.SetMapIndex(key, value)
+	}
+
+	return nil
+}
+
+// isKeyConvertible is stricter than reflect.Type.ConvertibleTo, so that, for
+// example, integer primary key is not converted to string key as a rune
+func isKeyConvertible(from, to reflect.Type) bool {
+	if from.AssignableTo(to) {
+		return true
+	}
+
+	if from.Kind() == reflect.String || to.Kind() == reflect.String {
+		return from.Kind() == to.Kind()
+	}
+
+	return isNumberKind(from.Kind()) && isNumberKind(to.Kind())
+}
+
+func isNumberKind(kind reflect.Kind) bool {
+	switch kind {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return true
+	}
+	return false
+}
+
+func primaryKeyFor(meta *metadata, record interface{}, keyType reflect.Type) (reflect.Value, error) {
+	idField := meta.primary
+	if err := ensureHasID(record, idField); err != nil {
+		return reflect.Value{}, err
+	}
+
+	if err := populateFieldValue(record, &idField); err != nil {
+		return reflect.Value{}, fmt.Errorf("Unable to fetch primary field of record %+v - %s", record, err)
+	}
+
+	key := reflect.ValueOf(idField.Value)
+	if !isKeyConvertible(key.Type(), keyType) {
+		return reflect.Value{}, fmt.Errorf("Unable to use primary field of type %s as a key of type %s", key.Type(), keyType)
+	}
+
+	return key.Convert(keyType), nil
+}
+
+func recordCallbackFor(record interface{}, fn interface{}) (func(interface{}) error, error) {
+	return callbackFor(reflect.TypeOf(zeroValueOf(record)), fn)
+}
+
+func callbackFor(argType reflect.Type, fn interface{}) (func(interface{}) error, error) {
+	fv := reflect.ValueOf(fn)
+	errorType := reflect.TypeOf((*error)(nil)).Elem()
+
+	if fv.Kind() != reflect.Func ||
+		fv.Type().NumIn() != 1 || fv.Type().In(0) != argType ||
+		fv.Type().NumOut() != 1 || fv.Type().Out(0) != errorType {
+		return nil, fmt.Errorf("Callback is required to be of type func(%s) error, but got: %T", argType, fn)
+	}
+
+	return func(arg interface{}) error {
+		out := fv.Call([]reflect.Value{reflect.ValueOf(arg)})
+		if err, ok := out[0].Interface().(error); ok {
+			return err
+		}
+		return nil
+	}, nil
+}
+
+func ensureHasID(record interface{}, idField field.Field) error {
+	if !idField.Primary {
+		return fmt.Errorf(
+			"Record has no primary field - type=%s - Use `rebecca_primary:\"true\"` annotation",
+			typeName(record),
+		)
+	}
+	return nil
+}
+
+func pageColumnsFor(meta *metadata, order string, orderBy []context.Ordering) (context.Cursor, error) {
+	columns := context.Cursor{}
+	hasPrimary := false
+
+	if len(orderBy) > 0 && order != "" {
+		return nil, fmt.Errorf("Unable to combine Order '%s' with OrderBy", order)
+	}
+
+	for _, o := range orderBy {
+		if o.Nulls != context.NullsDefault {
+			return nil, fmt.Errorf("Unable to paginate with %s ordering of column %s", o.Nulls, o.Column)
+		}
+
+		f, ok := fieldByName(meta.fields, o.Column)
+		if !ok {
+			return nil, fmt.Errorf("Order column %s is not a field of the model", o.Column)
+		}
+		hasPrimary = hasPrimary || f.Primary
+
+		columns = append(columns, context.CursorField{Field: f, Descending: o.Descending})
+	}
+
+	if strings.TrimSpace(order) != "" {
+		for _, part := range strings.Split(order, ",") {
+			words := strings.Fields(part)
+			if len(words) == 0 || len(words) > 2 {
+				return nil, fmt.Errorf("Order is required to be a list of columns with optional ASC or DESC, but got: '%s'", order)
+			}
+
+			column := context.CursorField{}
+			if len(words) == 2 {
+				switch strings.ToUpper(words[1]) {
+				case "ASC":
+				case "DESC":
+					column.Descending = true
+				default:
+					return nil, fmt.Errorf("Order is required to be a list of columns with optional ASC or DESC, but got: '%s'", order)
+				}
+			}
+
+			f, ok := fieldByDriverName(meta, words[0])
+			if !ok {
+				return nil, fmt.Errorf("Order column %s is not a field of the model", words[0])
+			}
+			column.Field = f
+			hasPrimary = hasPrimary || f.Primary
+
+			columns = append(columns, column)
+		}
+	}
+
+	if !hasPrimary {
+		if !meta.primary.Primary {
+			return nil, errors.New("Model has no primary field to order by - Use `rebecca_primary:\"true\"` annotation")
+		}
+		columns = append(columns, context.CursorField{Field: meta.primary})
+	}
+
+	return columns, nil
+}
+
+// orderByFor validates structured ordering against fields of the model and
+// replaces field names with driver names
+func orderByFor(meta *metadata, order string, orderBy []context.Ordering) ([]context.Ordering, error) {
+	if order != "" {
+		return nil, fmt.Errorf("Unable to combine Order '%s' with OrderBy", order)
+	}
+
+	validated := []context.Ordering{}
+	for _, o := range orderBy {
+		f, ok := fieldByName(meta.fields, o.Column)
+		if !ok {
+			return nil, fmt.Errorf("Unable to order by unknown column %s", o.Column)
+		}
+
+		switch o.Nulls {
+		case context.NullsDefault, context.NullsFirst, context.NullsLast:
+		default:
+			return nil, fmt.Errorf("Unable to order by column %s with %s", o.Column, o.Nulls)
+		}
+
+		o.Column = f.DriverName
+		validated = append(validated, o)
+	}
+
+	return validated, nil
+}
+
+func fieldByDriverName(meta *metadata, driverName string) (field.Field, bool) {
+	for _, f := range meta.fields {
+		if f.DriverName == driverName {
+			return f, true
+		}
+	}
+	return field.Field{}, false
+}
+
+func fieldByName(fields []field.Field, name string) (field.Field, bool) {
+	for _, f := range fields {
+		if f.Name == name || f.DriverName == name {
+			return f, true
+		}
+	}
+	return field.Field{}, false
+}
+
+func reversedColumns(columns context.Cursor) context.Cursor {
+	reversed := context.Cursor{}
+	for _, column := range columns {
+		column.Descending = !column.Descending
+		reversed = append(reversed, column)
+	}
+	return reversed
+}
+
+func orderingsFor(columns context.Cursor) []context.Ordering {
+	orderings := []context.Ordering{}
+	for _, column := range columns {
+		orderings = append(orderings, context.Ordering{Column: column.DriverName, Descending: column.Descending})
+	}
+	return orderings
+}
+
+func orderFor(columns context.Cursor) string {
+	parts := []string{}
+	for _, column := range columns {
+		direction := "ASC"
+		if column.Descending {
+			direction = "DESC"
+		}
+		parts = append(parts, column.DriverName+" "+direction)
+	}
+	return strings.Join(parts, ", ")
+}
+
+func reverseSlice(slice reflect.Value) {
+	swap := reflect.Swapper(slice.Interface())
+	for i, j := 0, slice.Len()-1; i < j; i, j = i+1, j-1 {
+		swap(i, j)
+	}
+}
+
+type pageToken struct {
+	Order  string            `json:"o"`
+	Values []json.RawMessage `json:"v"`
+}
+
+func encodePageToken(meta *metadata, columns context.Cursor, record interface{}) (string, error) {
+	token := pageToken{Order: meta.tablename + ":" + orderFor(columns)}
+
+	for _, column := range columns {
+		f := column.Field
+		if err := populateFieldValue(record, &f); err != nil {
+			return "", err
+		}
+
+		value, err := json.Marshal(f.Value)
+		if err != nil {
+			return "", fmt.Errorf("Unable to encode value of field %s - %s", f.Name, err)
+		}
+		token.Values = append(token.Values, value)
+	}
+
+	payload, err := json.Marshal(token)
+	if err != nil {
+		return "", err
+	}
+
+	encoding := base64.RawURLEncoding
+	return encoding.EncodeToString(payload) + "." + encoding.EncodeToString(pageTokenSignature(payload)), nil
+}
+
+func decodePageToken(meta *metadata, columns context.Cursor, encoded string) (context.Cursor, error) {
+	invalidToken := fmt.Errorf("Invalid page token '%s'", encoded)
+	encoding := base64.RawURLEncoding
+
+	parts := strings.Split(encoded, ".")
+	if len(parts) != 2 {
+		return nil, invalidToken
+	}
+
+	payload, err := encoding.DecodeString(parts[0])
+	if err != nil {
+		return nil, invalidToken
+	}
+
+	signature, err := encoding.DecodeString(parts[1])
+	if err != nil || !hmac.Equal(signature, pageTokenSignature(payload)) {
+		return nil, invalidToken
+	}
+
+	token := pageToken{}
+	if err := json.Unmarshal(payload, &token); err != nil {
+		return nil, invalidToken
+	}
+
+	if token.Order != meta.tablename+":"+orderFor(columns) || len(token.Values) != len(columns) {
+		return nil, fmt.Errorf("Page token '%s' was issued for different model or order", encoded)
+	}
+
+	cursor := context.Cursor{}
+	for i, column := range columns {
+		value := reflect.New(column.Ty)
+		if err := json.Unmarshal(token.Values[i], value.Interface()); err != nil {
+			return nil, invalidToken
+		}
+
+		column.Value = value.Elem().Interface()
+		cursor = append(cursor, column)
+	}
+
+	return cursor, nil
+}
+
+func pageTokenSignature(payload []byte) []byte {
+	mac := hmac.New(sha256.New, getPageTokenKey())
+	mac.Write(payload)
+	return mac.Sum(nil)
+}
+
+func getPageTokenKey() []byte {
+	pageTokenKeyMux.RLock()
+	defer pageTokenKeyMux.RUnlock()
+
+	return pageTokenKey
+}
+
+func newPageTokenKey() []byte {
+	key := make([]byte, 32)
+	if _, err := rand.Read(key); err != nil {
+		panic("rebecca: Unable to generate page token key - " + err.Error())
+	}
+	return key
+}
+
+func andConditions(conditions ...condition.Condition) condition.Condition {
+	result := condition.And{}
+	for _, c := range conditions {
+		if c != nil {
+			result = append(result, c)
+		}
+	}
+
+	switch len(result) {
+	case 0:
+		return nil
+	case 1:
+		return result[0]
+	}
+	return result
+}
+
+// validatedCondition checks that columns of the condition are fields of the
+// model, by name or by driver name, and replaces them with driver names, so
+// that structured conditions never put arbitrary SQL into the query. Only
+// fields marked with rebecca_expression are rendered as expressions. Columns
+// qualified with a tablename, like "posts.title", are allowed for joins,
+// drivers quote them as identifiers
+func validatedCondition(meta *metadata, cond condition.Condition) (condition.Condition, error) {
+	column := func(name string) (string, error) {
+		if f, ok := fieldByName(meta.fields, name); ok {
+			return f.DriverName, nil
+		}
+
+		if qualifiedColumn.MatchString(name) {
+			return name, nil
+		}
+
+		return "", fmt.Errorf("Unable to use unknown column %s in condition", name)
+	}
+
+	switch c := cond.(type) {
+	case nil:
+		return nil, nil
+
+	case condition.Compare:
+		switch c.Operator {
+		case condition.Eq, condition.NotEq, condition.Lt, condition.Lte, condition.Gt, condition.Gte:
+		default:
+			return nil, fmt.Errorf("Unable to compare column %s with unknown operator %s", c.Column, c.Operator)
+		}
+
+		var err error
+		c.Column, err = column(c.Column)
+		return c, err
+
+	case condition.In:
+		var err error
+		c.Column, err = column(c.Column)
+		return c, err
+
+	case condition.Like:
+		var err error
+		c.Column, err = column(c.Column)
+		return c, err
+
+	case condition.IsNull:
+		var err error
+		c.Column, err = column(c.Column)
+		return c, err
+
+	case condition.And:
+		validated := condition.And{}
+		for _, inner := range c {
+			v, err := validatedCondition(meta, inner)
+			if err != nil {
+				return nil, err
+			}
+			validated = append(validated, v)
+		}
+		return validated, nil
+
+	case condition.Or:
+		validated := condition.Or{}
+		for _, inner := range c {
+			v, err := validatedCondition(meta, inner)
+			if err != nil {
+				return nil, err
+			}
+			validated = append(validated, v)
+		}
+		return validated, nil
+
+	case condition.Not:
+		inner, err := validatedCondition(meta, c.Condition)
+		if err != nil {
+			return nil, err
+		}
+		return condition.Not{Condition: inner}, nil
+	}
+
+	return nil, fmt.Errorf("Unable to use unknown condition %T", cond)
+}
+
+var qualifiedColumn = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_$]*(\.[A-Za-z_][A-Za-z0-9_$]*)+$`)
+
+func exampleCondition(example interface{}, include []string) (condition.Condition, error) {
+	meta, err := getMetadata(example)
+	if err != nil {
+		return nil, err
+	}
+
+	fields, err := fieldsFor(&meta, example)
+	if err != nil {
+		return nil, fmt.Errorf("Unable to fetch fields for example %+v - %s", example, err)
+	}
+
+	included := map[string]bool{}
+	for _, name := range include {
+		found := false
+		for _, f := range fields {
+			if f.Name == name || f.DriverName == name {
+				included[f.Name] = true
+				found = true
+			}
+		}
+
+		if !found {
+			return nil, fmt.Errorf("Unable to include unknown field %s of example %+v", name, example)
+		}
+	}
+
+	conditions := condition.And{}
+	for _, f := range fields {
+		v := reflect.ValueOf(f.Value)
+		if !included[f.Name] && (!v.IsValid() || v.IsZero()) {
+			continue
+		}
+
+		if isNullValue(f.Value) {
+			conditions = append(conditions, condition.IsNull{Column: f.DriverName})
+			continue
+		}
+
+		conditions = append(conditions, condition.Compare{
+			Column:   f.DriverName,
+			Operator: condition.Eq,
+			Value:    f.Value,
+		})
+	}
+
+	return conditions, nil
+}
+
+// isNullValue tells if the value is stored as NULL: nil pointers, maps,
+// slices and Valuers, that have no value
+func isNullValue(value interface{}) bool {
+	v := reflect.ValueOf(value)
+	if !v.IsValid() {
+		return true
+	}
+
+	switch v.Kind() {
+	case reflect.Ptr, reflect.Interface, reflect.Map, reflect.Slice:
+		if v.IsNil() {
+			return true
+		}
+	}
+
+	if valuer, ok := value.(sqldriver.Valuer); ok {
+		plain, err := valuer.Value()
+		return err == nil && plain == nil
+	}
+
+	return false
+}
+
+var namedParameter = regexp.MustCompile(`(::)|:([A-Za-z_][A-Za-z0-9_]*)`)
+
+// bindNamed rewrites named parameters (:name) of the query to positional ones,
+// when the only argument is either Named or a struct with rebecca tags
+func bindNamed(query string, args []interface{}) (string, []interface{}, error) {
+	if len(args) != 1 {
+		return query, args, nil
+	}
+
+	values, strict, ok := namedValuesFor(args[0])
+	if !ok {
+		return query, args, nil
+	}
+
+	positions := map[string]int{}
+	bound := []interface{}{}
+	rewritten := ""
+	var bindErr error
+
+	for _, segment := range driver.SplitQuery(query) {
+		if !segment.Code {
+			rewritten += segment.Text
+			continue
+		}
+
+		rewritten += namedParameter.ReplaceAllStringFunc(segment.Text, func(match string) string {
+			if match == "::" {
+				return match
+			}
+
+			name := match[1:]
+			if _, ok := positions[name]; !ok {
+				value, ok := values[name]
+				if !ok {
+					bindErr = fmt.Errorf("Named parameter :%s is missing in %+v", name, args[0])
+					return match
+				}
+
+				bound = append(bound, value)
+				positions[name] = len(bound)
+			}
+
+			return fmt.Sprintf("$%d", positions[name])
+		})
+	}
+
+	if bindErr != nil {
+		return "", nil, bindErr
+	}
+
+	if !strict && len(positions) == 0 {
+		// struct is used as a plain positional argument then
+		return query, args, nil
+	}
+
+	if strict {
+		for name := range values {
+			if _, ok := positions[name]; !ok {
+				return "", nil, fmt.Errorf("Named parameter :%s is not used in query '%s'", name, query)
+			}
+		}
+	}
+
+	return rewritten, bound, nil
+}
+
+var placeholder = regexp.MustCompile(`\?\??|\$([0-9]+)`)
+
+// prepareQuery binds named parameters of the query and translates its
+// placeholders to the style of the driver
+func prepareQuery(query string, args []interface{}, style driver.PlaceholderStyle) (string, []interface{}, error) {
+	query, args, err := bindNamed(query, args)
+	if err != nil {
+		return "", nil, err
+	}
+
+	return translatePlaceholders(query, args, style)
+}
+
+// translatePlaceholders rewrites ? placeholders to $n ones and vice versa,
+// reordering args when needed. Queries without args are left as is, and so
+// are ? in queries with $n placeholders, because they are operators then
+// (for example, postgres jsonb operators). ?? stands for literal ? when ?
+// placeholders are translated to $n ones, and it is kept as is when $n
+// placeholders are translated to ? ones
+func translatePlaceholders(query string, args []interface{}, style driver.PlaceholderStyle) (string, []interface{}, error) {
+	if len(args) == 0 {
+		return query, args, nil
+	}
+
+	questions, dollars := 0, 0
+	forEachPlaceholder(query, func(match string) string {
+		switch {
+		case match == "?":
+			questions++
+		case strings.HasPrefix(match, "$"):
+			dollars++
+		}
+		return match
+	})
+
+	switch {
+	case style == driver.Dollar && dollars == 0:
+		position := 0
+		translated := forEachPlaceholder(query, func(match string) string {
+			if match == "??" {
+				return "?"
+			}
+
+			position++
+			return fmt.Sprintf("$%d", position)
+		})
+		return translated, args, nil
+
+	case style == driver.Question && dollars > 0:
+		if questions > 0 {
+			return "", nil, fmt.Errorf("Unable to mix ? and $n placeholders in query '%s'", query)
+		}
+
+		reordered := []interface{}{}
+		var translateErr error
+		translated := forEachPlaceholder(query, func(match string) string {
+			if match == "??" {
+				return match
+			}
+
+			n, _ := strconv.Atoi(match[1:])
+			if n < 1 || n > len(args) {
+				translateErr = fmt.Errorf("Placeholder %s is out of range of %d arguments in query '%s'", match, len(args), query)
+				return match
+			}
+
+			reordered = append(reordered, args[n-1])
+			return "?"
+		})
+
+		if translateErr != nil {
+			return "", nil, translateErr
+		}
+		return translated, reordered, nil
+	}
+
+	return query, args, nil
+}
+
+// forEachPlaceholder replaces every placeholder found outside of string
+// literals, quoted identifiers and comments with the result of fn
+func forEachPlaceholder(query string, fn func(match string) string) string {
+	result := ""
+	for _, segment := range driver.SplitQuery(query) {
+		if !segment.Code {
+			result += segment.Text
+			continue
+		}
+
+		result += placeholder.ReplaceAllStringFunc(segment.Text, fn)
+	}
+	return result
+}
+
+func namedValuesFor(arg interface{}) (map[string]interface{}, bool, bool) {
+	if named, ok := arg.(Named); ok {
+		return named, true, true
+	}
+
+	v := reflect.ValueOf(arg)
+	for valueHasElem(v) && !v.IsNil() {
+		v = v.Elem()
+	}
+
+	if v.Kind() != reflect.Struct {
+		return nil, false, false
+	}
+
+	values := map[string]interface{}{}
+	ty := v.Type()
+	for i := 0; i < ty.NumField(); i++ {
+		f := ty.Field(i)
+		name := f.Tag.Get("rebecca")
+		if name == "" || f.PkgPath != "" {
+			continue
+		}
+		values[name] = v.Field(i).Interface()
+	}
+
+	return values, false, len(values) > 0
+}
+
+// resolvedAssociation is for storing association together with the fields,
+// that link records: for belongs_to, own field is the foreign key and target
+// field is the primary key of associated model, and vice versa for has_one
+// and has_many
+type resolvedAssociation struct {
+	association
+
+	target      metadata
+	targetType  reflect.Type
+	ownField    field.Field
+	targetField field.Field
+}
+
+func associationFor(meta *metadata, name string) (association, error) {
+	for _, assoc := range meta.associations {
+		if assoc.name == name {
+			return assoc, nil
+		}
+	}
+	return association{}, fmt.Errorf("Unable to find association %s of %s", name, meta.modelTablename)
+}
+
+func resolveAssociation(meta *metadata, assoc association) (resolvedAssociation, error) {
+	targetType := assoc.ty
+	for typeHasElem(targetType) {
+		targetType = targetType.Elem()
+	}
+
+	target, err := getMetadata(reflect.New(targetType).Interface())
+	if err != nil {
+		return resolvedAssociation{}, err
+	}
+
+	resolved := resolvedAssociation{association: assoc, target: target, targetType: targetType}
+
+	if assoc.kind == belongsTo {
+		resolved.ownField, err = foreignKeyFor(meta, assoc.foreignKey, assoc.name)
+		resolved.targetField = target.primary
+	} else {
+		resolved.ownField = meta.primary
+		resolved.targetField, err = foreignKeyFor(&target, assoc.foreignKey, assoc.owner)
+	}
+
+	if err != nil && assoc.bare {
+		err = unknownOption(assoc, target.modelTablename)
+	}
+
+	if err != nil {
+		return resolvedAssociation{}, fmt.Errorf("Unable to resolve association %s - %s", assoc.name, err)
+	}
+
+	return resolved, nil
+}
+
+// unknownOption reports bare option of association tag, that is neither the
+// name of the field nor a column, which holds the foreign key
+func unknownOption(assoc association, tablename string) error {
+	return fmt.Errorf(
+		"Unknown option %s of association %s - it is neither the name of the field nor a column of %s, use foreign_key=column",
+		assoc.foreignKey, assoc.name, tablename,
+	)
+}
+
+// foreignKeyFor finds foreign key field either by its column, or by default
+// names derived from the name: name_id column or NameID field
+func foreignKeyFor(meta *metadata, foreignKey string, name string) (field.Field, error) {
+	candidates := []string{foreignKey}
+	if foreignKey == "" {
+		candidates = []string{snakeCase(name) + "_id", name + "ID"}
+	}
+
+	for _, candidate := range candidates {
+		if f, ok := fieldByName(meta.fields, candidate); ok {
+			return f, nil
+		}
+	}
+
+	return field.Field{}, fmt.Errorf("foreign key %s not found on %s", strings.Join(candidates, " or "), meta.modelTablename)
+}
+
+// preloadAssociations fetches associated records of all records for each
+// path of association names, like "Posts.Comments", with one query per
+// association. Associations of the path prefix are loaded first, each only
+// once
+func preloadAssociations(ctx *Context, meta *metadata, records interface{}, paths []string) error {
+	parents, err := parentsOf(records)
+	if err != nil {
+		return err
+	}
+
+	loaded := map[string][]reflect.Value{"": parents}
+	metas := map[string]metadata{"": *meta}
+	for _, path := range paths {
+		prefix := ""
+		for _, name := range strings.Split(path, ".") {
+			current := name
+			if prefix != "" {
+				current = prefix + "." + name
+			}
+
+			if _, ok := loaded[current]; !ok {
+				parentMeta := metas[prefix]
+				assoc, err := associationFor(&parentMeta, name)
+				if err != nil {
+					return err
+				}
+
+				target, err := loadAssociation(ctx, &parentMeta, assoc, loaded[prefix])
+				if err != nil {
+					return fmt.Errorf("Unable to load association %s - %s", current, err)
+				}
+
+				children, err := associatedOf(loaded[prefix], name)
+				if err != nil {
+					return fmt.Errorf("Unable to load association %s - %s", current, err)
+				}
+
+				loaded[current] = children
+				metas[current] = target
+			}
+
+			prefix = current
+		}
+	}
+
+	return nil
+}
+
+// loadAssociation fetches associated records of all parents at once and
+// assigns them to the association field of each parent. Parents are
+// addressable struct values. It returns metadata of associated model
+func loadAssociation(ctx *Context, meta *metadata, assoc association, parents []reflect.Value) (metadata, error) {
+	resolved, err := resolveAssociation(meta, assoc)
+	if err != nil {
+		return metadata{}, err
+	}
+
+	keys := []interface{}{}
+	seen := map[string]bool{}
+	for _, parent := range parents {
+		key, ok := keyOf(parent.FieldByName(resolved.ownField.Name))
+		if !ok || seen[fmt.Sprint(key)] {
+			continue
+		}
+		seen[fmt.Sprint(key)] = true
+		keys = append(keys, key)
+	}
+
+	targets := reflect.New(reflect.SliceOf(reflect.PtrTo(resolved.targetType)))
+	if len(keys) > 0 {
+		targetCtx := &Context{tx: ctx.tx, tenant: ctx.tenant}
+		if resolved.target.primary.Primary {
+			targetCtx.orderBy = []context.Ordering{{Column: resolved.target.primary.DriverName}}
+		}
+
+		if err := targetCtx.Where(targets.Interface(), In(resolved.targetField.DriverName, keys...)); err != nil {
+			return metadata{}, err
+		}
+	}
+
+	byKey := map[string][]reflect.Value{}
+	for i := 0; i < targets.Elem().Len(); i++ {
+		target := targets.Elem().Index(i)
+		if key, ok := keyOf(target.Elem().FieldByName(resolved.targetField.Name)); ok {
+			byKey[fmt.Sprint(key)] = append(byKey[fmt.Sprint(key)], target)
+		}
+	}
+
+	for _, parent := range parents {
+		var associated []reflect.Value
+		if key, ok := keyOf(parent.FieldByName(resolved.ownField.Name)); ok {
+			associated = byKey[fmt.Sprint(key)]
+		}
+		assignAssociated(parent.FieldByName(assoc.name), associated)
+	}
+
+	return resolved.target, nil
+}
+
+// associatedOf collects addressable associated records assigned to the
+// association field of parents, so that their own associations can be loaded
+func associatedOf(parents []reflect.Value, name string) ([]reflect.Value, error) {
+	children := []reflect.Value{}
+	for _, parent := range parents {
+		f := parent.FieldByName(name)
+		if f.Kind() == reflect.Ptr && f.IsNil() {
+			continue
+		}
+
+		associated, err := parentsOf(f.Addr().Interface())
+		if err != nil {
+			return nil, err
+		}
+		children = append(children, associated...)
+	}
+	return children, nil
+}
+
+// assignAssociated assigns pointers to associated records to the field of
+// type []T, []*T, *T or T. Single record fields are left untouched, when
+// there are no associated records
+func assignAssociated(f reflect.Value, targets []reflect.Value) {
+	if f.Kind() == reflect.Slice {
+		slice := reflect.MakeSlice(f.Type(), 0, len(targets))
+		for _, target := range targets {
+			if f.Type().Elem().Kind() != reflect.Ptr {
+				target = target.Elem()
+			}
+			slice = reflect.Append(slice, target)
+		}
+		f.Set(slice)
+		return
+	}
+
+	if len(targets) == 0 {
+		return
+	}
+
+	if f.Kind() == reflect.Ptr {
+		f.Set(targets[0])
+		return
+	}
+
+	f.Set(targets[0].Elem())
+}
+
+// parentsOf collects addressable struct values of the record or of records
+// of the slice or the map of pointers
+func parentsOf(records interface{}) ([]reflect.Value, error) {
+	v := reflect.ValueOf(records)
+	for valueHasElem(v) && !v.IsNil() {
+		v = v.Elem()
+	}
+
+	switch v.Kind() {
+	case reflect.Struct:
+		if !v.CanAddr() {
+			return nil, fmt.Errorf("Record is required to be a pointer, but got: %T", records)
+		}
+		return []reflect.Value{v}, nil
+
+	case reflect.Slice, reflect.Map:
+		parents := []reflect.Value{}
+		values := []reflect.Value{}
+		if v.Kind() == reflect.Slice {
+			for i := 0; i < v.Len(); i++ {
+				values = append(values, v.Index(i))
+			}
+		} else {
+			for _, key := range v.MapKeys() {
+				values = append(values, v.MapIndex(key))
+			}
+		}
+
+		for _, value := range values {
+			for valueHasElem(value) && !value.IsNil() {
+				value = value.Elem()
+			}
+
+			if !value.CanAddr() {
+				return nil, fmt.Errorf("Records are required to be addressable, but got: %T", records)
+			}
+			parents = append(parents, value)
+		}
+		return parents, nil
+	}
+
+	return nil, fmt.Errorf("Records are required to be a pointer to struct, a slice or a map, but got: %T", records)
+}
+
+// assignBelongsTo assigns primary keys of associated records, that are
+// saved already, to foreign keys of the record
+func assignBelongsTo(meta *metadata, record interface{}) error {
+	for _, assoc := range meta.associations {
+		if assoc.kind != belongsTo {
+			continue
+		}
+
+		resolved, err := resolveAssociation(meta, assoc)
+		if err != nil {
+			return err
+		}
+
+		parents, err := parentsOf(record)
+		if err != nil {
+			return err
+		}
+
+		for _, parent := range parents {
+			target := parent.FieldByName(assoc.name)
+			for valueHasElem(target) && !target.IsNil() {
+				target = target.Elem()
+			}
+
+			if target.Kind() != reflect.Struct {
+				continue
+			}
+
+			key, ok := keyOf(target.FieldByName(resolved.targetField.Name))
+			if !ok {
+				continue
+			}
+
+			if err := setKey(parent.FieldByName(resolved.ownField.Name), key); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// assignHas assigns primary key of the record to foreign keys of records
+// associated with has_one and has_many
+func assignHas(meta *metadata, record interface{}) error {
+	for _, assoc := range meta.associations {
+		if assoc.kind == belongsTo {
+			continue
+		}
+
+		resolved, err := resolveAssociation(meta, assoc)
+		if err != nil {
+			return err
+		}
+
+		parents, err := parentsOf(record)
+		if err != nil {
+			return err
+		}
+
+		for _, parent := range parents {
+			key, ok := keyOf(parent.FieldByName(resolved.ownField.Name))
+			if !ok {
+				continue
+			}
+
+			children, err := parentsOf(parent.FieldByName(assoc.name).Addr().Interface())
+			if err != nil {
+				continue
+			}
+
+			for _, child := range children {
+				if err := setKey(child.FieldByName(resolved.targetField.Name), key); err != nil {
+					return err
+				}
+			}
+		}
+	}
+
+	return nil
+}
+
+// keyOf fetches plain value of the key field, dereferencing pointers and
+// values of nullable types, like sql.NullInt64. It returns false for zero
+// and NULL keys
+func keyOf(f reflect.Value) (interface{}, bool) {
+	for f.Kind() == reflect.Ptr {
+		if f.IsNil() {
+			return nil, false
+		}
+		f = f.Elem()
+	}
+
+	if valuer, ok := f.Interface().(sqldriver.Valuer); ok {
+		value, err := valuer.Value()
+		if err != nil || value == nil {
+			return nil, false
+		}
+		f = reflect.ValueOf(value)
+	}
+
+	if f.IsZero() {
+		return nil, false
+	}
+	return f.Interface(), true
+}
+
+// setKey assigns plain key value to the key field, allocating pointers and
+// scanning into nullable types, like sql.NullInt64
+func setKey(f reflect.Value, key interface{}) error {
+	if f.Kind() == reflect.Ptr {
+		value := reflect.New(f.Type().Elem())
+		if err := setKey(value.Elem(), key); err != nil {
+			return err
+		}
+		f.Set(value)
+		return nil
+	}
+
+	if scanner, ok := f.Addr().Interface().(sql.Scanner); ok {
+		return scanner.Scan(key)
+	}
+
+	value := reflect.ValueOf(key)
+	if !isKeyConvertible(value.Type(), f.Type()) {
+		return fmt.Errorf("Unable to assign key of type %s to field of type %s", value.Type(), f.Type())
+	}
+
+	f.Set(value.Convert(f.Type()))
 	return nil
 }
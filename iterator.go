@@ -0,0 +1,72 @@
+package rebecca
+
+// This file contains thin exported methods related to Iterator only.
+//
+// For unexported functions see: helpers.go
+//
+// For Context see: context.go
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/waterlink/rebecca/driver"
+)
+
+// Iterator is for fetching records lazily one by one, without loading the
+// whole result set into memory. It is created with Context.Iterate
+type Iterator struct {
+	rows   driver.Rows
+	closed bool
+	err    error
+}
+
+// Next is for advancing to the next record. It returns false when there are
+// no more records or when an error has occurred, check Err in that case
+func (it *Iterator) Next() bool {
+	if it.closed {
+		return false
+	}
+
+	if !it.rows.Next() {
+		it.err = it.rows.Err()
+		it.Close()
+		return false
+	}
+
+	return true
+}
+
+// Scan is for assigning current record's fields to the given record
+func (it *Iterator) Scan(record interface{}) error {
+	if it.closed {
+		return errors.New("Unable to scan record - Iterator is closed")
+	}
+
+	fields, err := it.rows.Fields()
+	if err != nil {
+		return fmt.Errorf("Unable to scan record - %s", err)
+	}
+
+	if err := setFields(record, fields); err != nil {
+		return fmt.Errorf("Unable to scan record - %s", err)
+	}
+
+	return nil
+}
+
+// Close is for releasing resources held by the iterator. It is safe to call
+// it multiple times
+func (it *Iterator) Close() error {
+	if it.closed {
+		return nil
+	}
+
+	it.closed = true
+	return it.rows.Close()
+}
+
+// Err is for fetching an error encountered during the iteration, if any
+func (it *Iterator) Err() error {
+	return it.err
+}
@@ -0,0 +1,139 @@
+package rebecca
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/waterlink/rebecca/driver/fake"
+	"github.com/waterlink/rebecca/field"
+)
+
+func TestIterate(t *testing.T) {
+	SetupDriver(fake.NewDriver())
+
+	type Person struct {
+		ModelMetadata `tablename:"people"`
+
+		ID   int    `rebecca:"id" rebecca_primary:"true"`
+		Name string `rebecca:"name"`
+		Age  int    `rebecca:"age"`
+	}
+
+	p1 := &Person{Name: "John", Age: 37}
+	p2 := &Person{Name: "Sarah", Age: 26}
+	p3 := &Person{Name: "James", Age: 33}
+	people := []*Person{p1, p2, p3}
+
+	for _, p := range people {
+		if err := Save(p); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	it, err := Iterate(&Person{}, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer it.Close()
+
+	expected := []Person{*p1, *p2, *p3}
+	actual := []Person{}
+	for it.Next() {
+		p := Person{}
+		if err := it.Scan(&p); err != nil {
+			t.Fatal(err)
+		}
+		actual = append(actual, p)
+	}
+
+	if err := it.Err(); err != nil {
+		t.Fatal(err)
+	}
+
+	if !reflect.DeepEqual(actual, expected) {
+		t.Errorf("Expected %+v to equal to %+v", actual, expected)
+	}
+
+	if it.Next() {
+		t.Errorf("Expected exhausted iterator to not advance")
+	}
+
+	if err := it.Scan(&Person{}); err == nil {
+		t.Errorf("Expected Scan on exhausted iterator to fail")
+	}
+}
+
+func TestEach(t *testing.T) {
+	d := fake.NewDriver()
+	SetupDriver(d)
+
+	d.RegisterWhere("age < $1", func(record []field.Field, args ...interface{}) (bool, error) {
+		for _, f := range record {
+			if f.DriverName == "age" {
+				return f.Value.(int) < args[0].(int), nil
+			}
+		}
+
+		return false, fmt.Errorf("record %+v does not have age field", record)
+	})
+
+	type Person struct {
+		ModelMetadata `tablename:"people"`
+
+		ID   int    `rebecca:"id" rebecca_primary:"true"`
+		Name string `rebecca:"name"`
+		Age  int    `rebecca:"age"`
+	}
+
+	p1 := &Person{Name: "John", Age: 9}
+	p2 := &Person{Name: "Sarah", Age: 27}
+	p3 := &Person{Name: "James", Age: 11}
+	p4 := &Person{Name: "Monika", Age: 12}
+	people := []*Person{p1, p2, p3, p4}
+
+	for _, p := range people {
+		if err := Save(p); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	expected := []Person{*p1, *p3}
+	actual := []Person{}
+	err := Each(&Person{}, "age < $1", []interface{}{12}, func(p *Person) error {
+		actual = append(actual, *p)
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !reflect.DeepEqual(actual, expected) {
+		t.Errorf("Expected %+v to equal to %+v", actual, expected)
+	}
+
+	stop := errors.New("stop here")
+	expected = []Person{*p1, *p2}
+	actual = []Person{}
+	err = Each(&Person{}, "", nil, func(p *Person) error {
+		actual = append(actual, *p)
+		if len(actual) == 2 {
+			return stop
+		}
+		return nil
+	})
+	if err != stop {
+		t.Errorf("Expected Each to return %s, but got: %s", stop, err)
+	}
+
+	if !reflect.DeepEqual(actual, expected) {
+		t.Errorf("Expected %+v to equal to %+v", actual, expected)
+	}
+
+	err = Each(&Person{}, "", nil, func(p Person) error { return nil })
+	expectedErr := "Callback is required to be of type func(*rebecca.Person) error, but got: func(rebecca.Person) error"
+	if errRepr(err) != expectedErr {
+		t.Errorf("Expected %s to equal %s", errRepr(err), expectedErr)
+	}
+}
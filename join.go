@@ -0,0 +1,36 @@
+package rebecca
+
+// This file contains thin exported functions related to joins only.
+//
+// For unexported functions see: helpers.go
+
+import (
+	"fmt"
+	"sync"
+)
+
+var (
+	definedJoins    = map[string]map[string]string{}
+	definedJoinsMux = &sync.RWMutex{}
+)
+
+// DefineJoin is for defining named join for the model, so that it can be
+// referenced by name in Context.Joins, for example:
+//
+//	rebecca.DefineJoin(&Post{}, "author", "JOIN people ON people.id = posts.author_id")
+//	ctx := &rebecca.Context{Joins: []string{"author"}}
+func DefineJoin(record interface{}, name string, clause string) error {
+	meta, err := getMetadata(record)
+	if err != nil {
+		return fmt.Errorf("Unable to define join %s - %s", name, err)
+	}
+
+	definedJoinsMux.Lock()
+	defer definedJoinsMux.Unlock()
+
+	if definedJoins[meta.modelTablename] == nil {
+		definedJoins[meta.modelTablename] = map[string]string{}
+	}
+	definedJoins[meta.modelTablename][name] = clause
+	return nil
+}
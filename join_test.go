@@ -0,0 +1,68 @@
+package rebecca
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/waterlink/rebecca/driver/fake"
+)
+
+func TestJoins(t *testing.T) {
+	d := fake.NewDriver()
+	SetupDriver(d)
+
+	type Post struct {
+		ModelMetadata `tablename:"posts"`
+
+		ID       int    `rebecca:"id" rebecca_primary:"true"`
+		Title    string `rebecca:"title"`
+		AuthorID int    `rebecca:"author_id"`
+	}
+
+	if err := DefineJoin(&Post{}, "author", "JOIN people ON people.id = posts.author_id"); err != nil {
+		t.Fatal(err)
+	}
+
+	examples := map[string]struct {
+		joins    []string
+		expected []string
+		err      string
+	}{
+		"with defined join": {
+			joins:    []string{"author"},
+			expected: []string{"JOIN people ON people.id = posts.author_id"},
+		},
+
+		"with raw join": {
+			joins:    []string{"LEFT JOIN comments ON comments.post_id = posts.id", "author"},
+			expected: []string{"LEFT JOIN comments ON comments.post_id = posts.id", "JOIN people ON people.id = posts.author_id"},
+		},
+
+		"with unknown join": {
+			joins: []string{"comments"},
+			err:   "Unable to find join comments defined for posts",
+		},
+	}
+
+	for info, e := range examples {
+		t.Log(info)
+		actual, err := joinsFor("posts", e.joins)
+		if errRepr(err) != errRepr(nil) || e.err != "" {
+			if errRepr(err) != e.err {
+				t.Errorf("Expected %s to equal %s", errRepr(err), e.err)
+			}
+			continue
+		}
+
+		if !reflect.DeepEqual(actual, e.expected) {
+			t.Errorf("Expected %+v to equal %+v", actual, e.expected)
+		}
+	}
+
+	ctx := &Context{Joins: []string{"author"}}
+	err := ctx.All(&[]Post{})
+	expectedErr := "Unable to fetch all records - Fake driver does not support joins, but got: [JOIN people ON people.id = posts.author_id]"
+	if errRepr(err) != expectedErr {
+		t.Errorf("Expected %s to equal %s", errRepr(err), expectedErr)
+	}
+}
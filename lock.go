@@ -0,0 +1,18 @@
+package rebecca
+
+// This file contains thin exported variables related to row locking only.
+//
+// For Context see: context.go
+
+import "github.com/waterlink/rebecca/context"
+
+var (
+	// ForUpdate is for locking fetched records exclusively until the end of
+	// transaction. Use ForUpdate.NoWait() or ForUpdate.SkipLocked() to not
+	// wait for records locked by other transactions
+	ForUpdate = context.Lock{Strength: context.ForUpdate}
+
+	// ForShare is for locking fetched records against updates until the end
+	// of transaction, other transactions are still able to share the lock
+	ForShare = context.Lock{Strength: context.ForShare}
+)
@@ -0,0 +1,201 @@
+package rebecca
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/waterlink/rebecca/driver/fake"
+)
+
+func TestLock(t *testing.T) {
+	d := fake.NewDriver()
+	SetupDriver(d)
+
+	type Account struct {
+		ModelMetadata `tablename:"accounts"`
+
+		ID      int `rebecca:"id" rebecca_primary:"true"`
+		Balance int `rebecca:"balance"`
+	}
+
+	a1 := &Account{Balance: 100}
+	a2 := &Account{Balance: 200}
+	for _, a := range []*Account{a1, a2} {
+		if err := Save(a); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	ctx := &Context{Lock: ForUpdate}
+	err := ctx.All(&[]Account{})
+	expectedErr := "Unable to lock records outside of transaction - FOR UPDATE"
+	if errRepr(err) != expectedErr {
+		t.Errorf("Expected %s to equal %s", errRepr(err), expectedErr)
+	}
+
+	tx1, err := Begin()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer tx1.Rollback()
+
+	tx2, err := Begin()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer tx2.Rollback()
+
+	locked := &Account{}
+	if err := tx1.GetForUpdate(locked, a1.ID); err != nil {
+		t.Fatal(err)
+	}
+
+	if !reflect.DeepEqual(locked, a1) {
+		t.Errorf("Expected %+v to equal %+v", locked, a1)
+	}
+
+	type Entry struct {
+		ModelMetadata `tablename:"entries"`
+
+		Balance int `rebecca:"balance"`
+	}
+
+	err = tx1.GetForUpdate(&Entry{}, 1)
+	expectedErr = "Record has no primary field - type=github.com/waterlink/rebecca.Entry - Use `rebecca_primary:\"true\"` annotation"
+	if errRepr(err) != expectedErr {
+		t.Errorf("Expected %s to equal %s", errRepr(err), expectedErr)
+	}
+
+	err = tx2.Context(&Context{Lock: ForShare.NoWait()}).First(&Account{}, Eq("id", a1.ID))
+	expectedErr = "Unable to fetch specific records - Unable to get first record - Fake driver is unable to obtain lock FOR SHARE on accounts:1"
+	if errRepr(err) != expectedErr {
+		t.Errorf("Expected %s to equal %s", errRepr(err), expectedErr)
+	}
+
+	expected := []Account{*a2}
+	actual := []Account{}
+	if err := tx2.Context(&Context{Lock: ForUpdate.SkipLocked(), Limit: 1}).All(&actual); err != nil {
+		t.Fatal(err)
+	}
+
+	if !reflect.DeepEqual(actual, expected) {
+		t.Errorf("Expected %+v to equal %+v", actual, expected)
+	}
+
+	waited := &Account{}
+	done := make(chan error)
+	go func() {
+		done <- tx2.GetForUpdate(waited, a1.ID)
+	}()
+
+	select {
+	case err := <-done:
+		t.Fatalf("Expected lock to wait for the first transaction, but got: %v", err)
+	case <-time.After(20 * time.Millisecond):
+	}
+
+	locked.Balance -= 30
+	if err := tx1.Save(locked); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := tx1.Commit(); err != nil {
+		t.Fatal(err)
+	}
+
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatal(err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Expected lock to be obtained after the first transaction has finished")
+	}
+
+	if waited.Balance != 70 {
+		t.Errorf("Expected balance committed by the first transaction to be read after the wait, but got: %d", waited.Balance)
+	}
+
+	waited.Balance -= 30
+	if err := tx2.Save(waited); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := tx2.Commit(); err != nil {
+		t.Fatal(err)
+	}
+
+	final := &Account{}
+	if err := Get(final, a1.ID); err != nil {
+		t.Fatal(err)
+	}
+
+	if final.Balance != 40 {
+		t.Errorf("Expected %d to equal 40", final.Balance)
+	}
+}
+
+func TestLockWaitDoesNotHoldDriver(t *testing.T) {
+	d := fake.NewDriver()
+	SetupDriver(d)
+
+	type Account struct {
+		ModelMetadata `tablename:"accounts"`
+
+		ID      int `rebecca:"id" rebecca_primary:"true"`
+		Balance int `rebecca:"balance"`
+	}
+
+	a := &Account{Balance: 100}
+	if err := Save(a); err != nil {
+		t.Fatal(err)
+	}
+
+	tx1, err := Begin()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer tx1.Rollback()
+
+	tx2, err := Begin()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer tx2.Rollback()
+
+	if err := tx1.GetForUpdate(&Account{}, a.ID); err != nil {
+		t.Fatal(err)
+	}
+
+	locked := make(chan error)
+	go func() {
+		locked <- tx2.GetForUpdate(&Account{}, a.ID)
+	}()
+	time.Sleep(20 * time.Millisecond)
+
+	setup := make(chan struct{})
+	go func() {
+		SetupDriver(d)
+		close(setup)
+	}()
+
+	select {
+	case <-setup:
+	case <-time.After(time.Second):
+		t.Fatal("Expected driver setup not to wait for the lock")
+	}
+
+	if err := tx1.Commit(); err != nil {
+		t.Fatal(err)
+	}
+
+	select {
+	case err := <-locked:
+		if err != nil {
+			t.Fatal(err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Expected lock to be obtained after the first transaction has finished")
+	}
+}
@@ -1,12 +1,72 @@
 package rebecca
 
-import "github.com/waterlink/rebecca/field"
+import (
+	"reflect"
+	"sync"
+
+	"github.com/waterlink/rebecca/field"
+)
+
+var (
+	metadataCache    = map[reflect.Type]metadata{}
+	metadataCacheMux = &sync.RWMutex{}
+)
 
 // ModelMetadata is for storing any metadata for the whole model
 type ModelMetadata struct{}
 
+// TableNamer is for models, table name of which is known only at runtime,
+// for example, monthly partitions of events. TableName overrides tablename
+// tag for every read and write of the record. When fetching into slices, it
+// is called on the zero value of the model. Table name without schema gets
+// the schema of schema tag
+type TableNamer interface {
+	TableName() string
+}
+
+// ContextTableNamer is for models, table name of which depends on the query
+// Context as well. record is passed as is, that is, a record or a pointer to
+// slice of records. It takes precedence over TableNamer
+type ContextTableNamer interface {
+	TableNameFor(ctx *Context, record interface{}) string
+}
+
 type metadata struct {
 	tablename string
+	schema    string
 	fields    []field.Field
 	primary   field.Field
+	tenant    field.Field
+
+	// tablename of the model as defined by its tags, it does not change when
+	// table name is overridden with TableNamer
+	modelTablename string
+
+	// type of the model, scopes are defined for it rather than for its table,
+	// which can be shared by several models
+	ty reflect.Type
+
+	// associations declared with rebecca_belongs_to, rebecca_has_one and
+	// rebecca_has_many tags, their fields are not columns
+	associations []association
+}
+
+// Kinds of associations
+const (
+	belongsTo = "belongs_to"
+	hasOne    = "has_one"
+	hasMany   = "has_many"
+)
+
+type association struct {
+	name       string
+	kind       string
+	foreignKey string
+	ty         reflect.Type
+
+	// foreign key is given as bare option, not with foreign_key=column
+	bare bool
+
+	// name of the model type, that declares the association
+	owner string
 }
@@ -24,13 +24,21 @@ func ExampleModelMetadata() {
 		// .. fields are defined here ..
 	}
 
+	type Invoice struct {
+		// Table of specific schema is provided with schema tag:
+		rebecca.ModelMetadata `tablename:"invoices" schema:"billing"`
+
+		// .. fields are defined here ..
+	}
+
 	type PersonCountByAge struct {
 		rebecca.ModelMetadata `tablename:"people"`
 
 		// Additionally you can use any expressions as a database mapping for
 		// fields, that your driver will allow, for example,
-		// count(distinct(field_name)):
-		Count int `rebecca:"count(distinct(id))"`
+		// count(distinct(field_name)). Such fields have to be marked as
+		// expressions, otherwise they are quoted as column names:
+		Count int `rebecca:"count(distinct(id))" rebecca_expression:"true"`
 		Age   int `rebecca:"age"`
 	}
 
@@ -46,3 +54,32 @@ func ExampleModelMetadata() {
 	// Ordering depends on your chosen driver and database.
 	fmt.Print(byAge)
 }
+
+func ExampleRegisterModel() {
+	// Person can not embed rebecca.ModelMetadata, for example, because it is
+	// generated:
+	type Person struct {
+		ID   int
+		Name string
+		Age  int
+	}
+
+	// So its metadata is declared outside of the type:
+	err := rebecca.RegisterModel(
+		Person{},
+		rebecca.Table("people"),
+		rebecca.Column("ID", "id", rebecca.Primary()),
+		rebecca.Column("Name", "name"),
+		rebecca.Column("Age", "age"),
+	)
+	if err != nil {
+		panic(err)
+	}
+
+	// At this point Person can be used as any other model:
+	people := []Person{}
+	if err := rebecca.Where(&people, rebecca.Gt("age", 18)); err != nil {
+		panic(err)
+	}
+	fmt.Print(people)
+}
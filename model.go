@@ -0,0 +1,173 @@
+package rebecca
+
+// This file contains thin exported functions related to registration of
+// models without ModelMetadata only.
+//
+// For unexported functions see: helpers.go
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+
+	"github.com/waterlink/rebecca/field"
+)
+
+var (
+	registeredModels    = map[reflect.Type]metadata{}
+	registeredModelsMux = &sync.RWMutex{}
+)
+
+// ModelOption is for declaring metadata of the model registered with
+// RegisterModel
+type ModelOption func(meta *metadata)
+
+// ColumnOption is for declaring metadata of the column declared with Column
+type ColumnOption func(f *field.Field)
+
+// RegisterModel is for declaring metadata of the model outside of its type,
+// so that structs, that can not embed ModelMetadata, for example, generated
+// ones, can be used as models, for example:
+//
+//	rebecca.RegisterModel(Person{}, rebecca.Table("people"), rebecca.Column("ID", "id", rebecca.Primary()))
+//
+// Only declared columns are mapped, the rest of the fields are left
+// untouched. Registered metadata takes precedence over tags
+func RegisterModel(record interface{}, options ...ModelOption) error {
+	meta := metadata{}
+	for _, option := range options {
+		option(&meta)
+	}
+
+	ty := reflect.TypeOf(record)
+	if ty == nil {
+		return fmt.Errorf("Unable to register model - Rebecca's model is required to be struct, but got: %+v", record)
+	}
+
+	for typeHasElem(ty) {
+		ty = ty.Elem()
+	}
+
+	if ty.Kind() != reflect.Struct {
+		return fmt.Errorf("Unable to register model - Rebecca's model is required to be struct, but got: %+v", record)
+	}
+
+	if meta.tablename == "" && !hasDynamicTablename(ty) {
+		return fmt.Errorf("Unable to register model - type=%s - table is missing", typeName(record))
+	}
+
+	if meta.tablename != "" && meta.schema != "" {
+		meta.tablename = meta.schema + "." + meta.tablename
+	}
+
+	meta.modelTablename = meta.tablename
+	if meta.modelTablename == "" {
+		meta.modelTablename = ty.PkgPath() + "." + ty.Name()
+	}
+
+	for i, f := range meta.fields {
+		structField, ok := ty.FieldByName(f.Name)
+		if !ok {
+			return fmt.Errorf("Unable to register model - type=%s - field %s not found", typeName(record), f.Name)
+		}
+
+		meta.fields[i].Ty = structField.Type
+		if f.Primary {
+			meta.primary = meta.fields[i]
+		}
+
+		if f.Tenant {
+			meta.tenant = meta.fields[i]
+		}
+	}
+
+	registeredModelsMux.Lock()
+	registeredModels[ty] = meta
+	registeredModelsMux.Unlock()
+
+	uncacheMetadata(ty)
+	return nil
+}
+
+// Register is for validating metadata of models eagerly, for example, at
+// startup, so that mistakes, like missing primary key, duplicated columns or
+// unexported fields, do not surface only when the model is first used. All
+// problems of all models are reported at once. Models registered with
+// RegisterModel are required to be declared before calling Register
+func Register(records ...interface{}) error {
+	problems := []string{}
+	for _, record := range records {
+		meta, found, err := inspectMetadata(record)
+		if err != nil {
+			problems = append(problems, fmt.Sprintf("Unable to fetch record's metadata - type=%s - %s", typeName(record), err))
+			continue
+		}
+
+		for _, problem := range found {
+			problems = append(problems, fmt.Sprintf("Unable to fetch record's metadata - type=%s - %s", typeName(record), problem))
+		}
+
+		for _, problem := range validateMetadata(modelType(record), meta) {
+			problems = append(problems, fmt.Sprintf("Invalid model - type=%s - %s", typeName(record), problem))
+		}
+	}
+
+	// Problems are joined manually instead of errors.Join, so that the error
+	// stays on one line like the rest of errors of rebecca
+	if len(problems) > 0 {
+		return fmt.Errorf("Unable to register models - %s", strings.Join(problems, "; "))
+	}
+
+	return nil
+}
+
+// Table is for declaring table name of the registered model
+func Table(tablename string) ModelOption {
+	return func(meta *metadata) {
+		meta.tablename = tablename
+	}
+}
+
+// Schema is for declaring schema of the table of the registered model
+func Schema(schema string) ModelOption {
+	return func(meta *metadata) {
+		meta.schema = schema
+	}
+}
+
+// Column is for declaring field of the registered model, that is mapped to
+// the column with driverName
+func Column(name string, driverName string, options ...ColumnOption) ModelOption {
+	return func(meta *metadata) {
+		f := field.Field{Name: name, DriverName: driverName}
+		for _, option := range options {
+			option(&f)
+		}
+		meta.fields = append(meta.fields, f)
+	}
+}
+
+// Primary is for marking the column as primary key, the same as
+// rebecca_primary tag
+func Primary() ColumnOption {
+	return func(f *field.Field) {
+		f.Primary = true
+	}
+}
+
+// Expression is for marking the column as expression, the same as
+// rebecca_expression tag
+func Expression() ColumnOption {
+	return func(f *field.Field) {
+		f.Expression = true
+	}
+}
+
+// Tenant is for marking the column as identifying tenant of the record, the
+// same as rebecca_tenant tag
+func Tenant() ColumnOption {
+	return func(f *field.Field) {
+		f.Tenant = true
+	}
+}
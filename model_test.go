@@ -0,0 +1,255 @@
+package rebecca
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/waterlink/rebecca/driver/fake"
+)
+
+type generatedPerson struct {
+	state int
+
+	ID   int
+	Name string
+	Age  int
+}
+
+func TestRegisterModel(t *testing.T) {
+	d := fake.NewDriver()
+	SetupDriver(d)
+
+	err := RegisterModel(
+		&generatedPerson{},
+		Table("people"),
+		Column("ID", "id", Primary()),
+		Column("Name", "name"),
+		Column("Age", "age"),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	meta, err := getMetadata(&[]generatedPerson{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if meta.tablename != "people" {
+		t.Errorf("Expected %s to equal people", meta.tablename)
+	}
+
+	if meta.primary.DriverName != "id" {
+		t.Errorf("Expected %s to equal id", meta.primary.DriverName)
+	}
+
+	expectedNames := []string{"id", "name", "age"}
+	names := []string{}
+	for _, f := range meta.fields {
+		names = append(names, f.DriverName)
+	}
+	if !reflect.DeepEqual(names, expectedNames) {
+		t.Errorf("Expected %+v to equal %+v", names, expectedNames)
+	}
+
+	p := &generatedPerson{state: 3, Name: "John", Age: 34}
+	if err := Save(p); err != nil {
+		t.Fatal(err)
+	}
+
+	fetched := &generatedPerson{}
+	if err := Get(fetched, p.ID); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := &generatedPerson{ID: p.ID, Name: "John", Age: 34}
+	if !reflect.DeepEqual(fetched, expected) {
+		t.Errorf("Expected %+v to equal %+v", fetched, expected)
+	}
+
+	people := []generatedPerson{}
+	if err := Where(&people, Gt("age", 30)); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(people, []generatedPerson{*expected}) {
+		t.Errorf("Expected %+v to equal %+v", people, []generatedPerson{*expected})
+	}
+}
+
+func TestRegisterModelErrors(t *testing.T) {
+	type Record struct {
+		ID int
+	}
+
+	examples := map[string]struct {
+		record  interface{}
+		options []ModelOption
+		err     string
+	}{
+		"without table": {
+			record:  &Record{},
+			options: []ModelOption{Column("ID", "id", Primary())},
+			err:     "Unable to register model - type=github.com/waterlink/rebecca.Record - table is missing",
+		},
+
+		"with unknown field": {
+			record:  &Record{},
+			options: []ModelOption{Table("records"), Column("Name", "name")},
+			err:     "Unable to register model - type=github.com/waterlink/rebecca.Record - field Name not found",
+		},
+
+		"with non-struct": {
+			record:  "hello",
+			options: []ModelOption{Table("records")},
+			err:     "Unable to register model - Rebecca's model is required to be struct, but got: hello",
+		},
+
+		"with schema": {
+			record:  &Record{},
+			options: []ModelOption{Table("records"), Schema("archive"), Column("ID", "id", Primary())},
+		},
+	}
+
+	for info, e := range examples {
+		t.Log(info)
+		err := RegisterModel(e.record, e.options...)
+		if errRepr(err) != errRepr(nil) || e.err != "" {
+			if errRepr(err) != e.err {
+				t.Errorf("Expected %s to equal %s", errRepr(err), e.err)
+			}
+			continue
+		}
+
+		meta, err := getMetadata(e.record)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if meta.tablename != "archive.records" {
+			t.Errorf("Expected %s to equal archive.records", meta.tablename)
+		}
+	}
+}
+
+func TestRegister(t *testing.T) {
+	type Valid struct {
+		ModelMetadata `tablename:"valids"`
+
+		ID   int    `rebecca:"id" rebecca_primary:"true"`
+		Name string `rebecca:"name"`
+	}
+
+	type Invalid struct {
+		ModelMetadata `tablename:"invalids"`
+
+		ID       int    `rebecca:"id"`
+		Name     string `rebecca:"name"`
+		Nickname string `rebecca:"name"`
+		secret   string `rebecca:"secret"`
+	}
+
+	type TwoPrimaries struct {
+		ModelMetadata `tablename:"two_primaries"`
+
+		ID    int `rebecca:"id" rebecca_primary:"true"`
+		Other int `rebecca:"other" rebecca_primary:"true"`
+	}
+
+	type MissingMetadata struct {
+		ID int
+	}
+
+	type SeveralProblems struct {
+		ModelMetadata
+
+		ID       int     `rebecca:"id"`
+		WriterID int     `rebecca:"writer_id"`
+		Editor   int     `rebecca:"writer_id"`
+		Writer   *Writer `rebecca_belongs_to:"Author"`
+	}
+
+	type Generated struct {
+		ID int
+	}
+
+	if err := RegisterModel(&Generated{}, Table("generated"), Column("ID", "id", Primary())); err != nil {
+		t.Fatal(err)
+	}
+
+	examples := map[string]struct {
+		records []interface{}
+		err     string
+	}{
+		"with valid models": {
+			records: []interface{}{&Valid{}, &Generated{}},
+			err:     errRepr(nil),
+		},
+
+		"with invalid models": {
+			records: []interface{}{&Valid{}, &Invalid{}, &TwoPrimaries{}, &MissingMetadata{}},
+			err: "Unable to register models - " +
+				"Invalid model - type=github.com/waterlink/rebecca.Invalid - column name is duplicated; " +
+				"Invalid model - type=github.com/waterlink/rebecca.Invalid - field secret is unexported; " +
+				"Invalid model - type=github.com/waterlink/rebecca.Invalid - primary field is missing; " +
+				"Invalid model - type=github.com/waterlink/rebecca.TwoPrimaries - primary field is required to be only one, but got: 2; " +
+				"Unable to fetch record's metadata - type=github.com/waterlink/rebecca.MissingMetadata - Rebecca's model is required to embed rebecca.ModelMetadata",
+		},
+
+		"with several problems of one model": {
+			records: []interface{}{&SeveralProblems{}},
+			err: "Unable to register models - " +
+				"Unable to fetch record's metadata - type=github.com/waterlink/rebecca.SeveralProblems - tablename tag metadata is missing on ModelMetadata; " +
+				"Unable to fetch record's metadata - type=github.com/waterlink/rebecca.SeveralProblems - Unknown option Author of association Writer - it is neither the name of the field nor a column of github.com/waterlink/rebecca.SeveralProblems, use foreign_key=column; " +
+				"Invalid model - type=github.com/waterlink/rebecca.SeveralProblems - column writer_id is duplicated; " +
+				"Invalid model - type=github.com/waterlink/rebecca.SeveralProblems - primary field is missing",
+		},
+	}
+
+	for info, e := range examples {
+		t.Log(info)
+		err := Register(e.records...)
+		if errRepr(err) != e.err {
+			t.Errorf("Expected %s to equal %s", errRepr(err), e.err)
+		}
+	}
+}
+
+func TestMetadataCache(t *testing.T) {
+	type Cached struct {
+		ModelMetadata `tablename:"cached"`
+
+		ID int `rebecca:"id" rebecca_primary:"true"`
+	}
+
+	if _, err := getMetadata(&[]Cached{}); err != nil {
+		t.Fatal(err)
+	}
+
+	meta, ok := cachedMetadata(reflect.TypeOf(Cached{}))
+	if !ok {
+		t.Fatal("Expected metadata to be cached")
+	}
+
+	meta.fields[0].DriverName = "changed"
+	again, err := getMetadata(&Cached{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if again.fields[0].DriverName != "id" {
+		t.Errorf("Expected cached metadata to stay intact, but got: %s", again.fields[0].DriverName)
+	}
+
+	if err := RegisterModel(&Cached{}, Table("cached_elsewhere"), Column("ID", "id", Primary())); err != nil {
+		t.Fatal(err)
+	}
+
+	registered, err := getMetadata(&Cached{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if registered.tablename != "cached_elsewhere" {
+		t.Errorf("Expected %s to equal cached_elsewhere", registered.tablename)
+	}
+}
@@ -0,0 +1,317 @@
+package rebecca
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/waterlink/rebecca/driver"
+	"github.com/waterlink/rebecca/driver/fake"
+	"github.com/waterlink/rebecca/field"
+)
+
+func TestBindNamed(t *testing.T) {
+	type Filter struct {
+		MinAge int    `rebecca:"min_age"`
+		Name   string `rebecca:"name"`
+		Other  string
+	}
+
+	type Plain struct {
+		Value string
+	}
+
+	examples := map[string]struct {
+		query        string
+		args         []interface{}
+		expected     string
+		expectedArgs []interface{}
+		err          string
+	}{
+		"with Named": {
+			query:        "age > :min_age AND name = :name",
+			args:         []interface{}{Named{"min_age": 18, "name": "John"}},
+			expected:     "age > $1 AND name = $2",
+			expectedArgs: []interface{}{18, "John"},
+		},
+
+		"with repeated names": {
+			query:        "age > :age OR (name = :name AND age = :age)",
+			args:         []interface{}{Named{"age": 18, "name": "John"}},
+			expected:     "age > $1 OR (name = $2 AND age = $1)",
+			expectedArgs: []interface{}{18, "John"},
+		},
+
+		"with struct": {
+			query:        "age > :min_age AND name = :name",
+			args:         []interface{}{&Filter{MinAge: 18, Name: "John", Other: "x"}},
+			expected:     "age > $1 AND name = $2",
+			expectedArgs: []interface{}{18, "John"},
+		},
+
+		"with casts, strings and comments": {
+			query:        "age::int > :age AND name <> ':name' AND \"a:b\" = 1 -- :name\n/* :name */",
+			args:         []interface{}{Named{"age": 18}},
+			expected:     "age::int > $1 AND name <> ':name' AND \"a:b\" = 1 -- :name\n/* :name */",
+			expectedArgs: []interface{}{18},
+		},
+
+		"with positional arguments": {
+			query:        "age > $1 AND name = $2",
+			args:         []interface{}{18, "John"},
+			expected:     "age > $1 AND name = $2",
+			expectedArgs: []interface{}{18, "John"},
+		},
+
+		"with struct without rebecca tags": {
+			query:        "value = $1",
+			args:         []interface{}{Plain{"x"}},
+			expected:     "value = $1",
+			expectedArgs: []interface{}{Plain{"x"}},
+		},
+
+		"with missing name": {
+			query: "age > :min_age AND name = :name",
+			args:  []interface{}{Named{"min_age": 18}},
+			err:   "Named parameter :name is missing in map[min_age:18]",
+		},
+
+		"with unknown name": {
+			query: "age > :min_age",
+			args:  []interface{}{Named{"min_age": 18, "max_age": 30}},
+			err:   "Named parameter :max_age is not used in query 'age > :min_age'",
+		},
+	}
+
+	for info, e := range examples {
+		t.Log(info)
+		actual, actualArgs, err := bindNamed(e.query, e.args)
+		if errRepr(err) != errRepr(nil) || e.err != "" {
+			if errRepr(err) != e.err {
+				t.Errorf("Expected %s to equal %s", errRepr(err), e.err)
+			}
+			continue
+		}
+
+		if actual != e.expected {
+			t.Errorf("Expected %s to equal %s", actual, e.expected)
+		}
+
+		if !reflect.DeepEqual(actualArgs, e.expectedArgs) {
+			t.Errorf("Expected %+v to equal %+v", actualArgs, e.expectedArgs)
+		}
+	}
+}
+
+func TestWhereNamed(t *testing.T) {
+	d := fake.NewDriver()
+	SetupDriver(d)
+
+	d.RegisterWhere("age > $1 AND name = $2", func(record []field.Field, args ...interface{}) (bool, error) {
+		age, name := 0, ""
+		for _, f := range record {
+			switch f.DriverName {
+			case "age":
+				age = f.Value.(int)
+			case "name":
+				name = f.Value.(string)
+			}
+		}
+
+		return age > args[0].(int) && name == args[1].(string), nil
+	})
+
+	type Person struct {
+		ModelMetadata `tablename:"people"`
+
+		ID   int    `rebecca:"id" rebecca_primary:"true"`
+		Name string `rebecca:"name"`
+		Age  int    `rebecca:"age"`
+	}
+
+	p1 := &Person{Name: "John", Age: 9}
+	p2 := &Person{Name: "John", Age: 27}
+	p3 := &Person{Name: "Sarah", Age: 27}
+	people := []*Person{p1, p2, p3}
+
+	for _, p := range people {
+		if err := Save(p); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	expected := []Person{*p2}
+	actual := []Person{}
+	if err := Where(&actual, "age > :min_age AND name = :name", Named{"min_age": 18, "name": "John"}); err != nil {
+		t.Fatal(err)
+	}
+
+	if !reflect.DeepEqual(actual, expected) {
+		t.Errorf("Expected %+v to equal to %+v", actual, expected)
+	}
+
+	if err := Exec("UPDATE people SET age = :age", Named{"age": 30}); err != nil {
+		t.Fatal(err)
+	}
+
+	actualExec := d.ReceivedExec()
+	expectedExec := fake.ReceivedExec{
+		Query: "UPDATE people SET age = $1",
+		Args:  []interface{}{30},
+	}
+	if !reflect.DeepEqual(actualExec, expectedExec) {
+		t.Errorf("Expected driver to receive exec %#v, but got %#v", expectedExec, actualExec)
+	}
+}
+
+func TestTranslatePlaceholders(t *testing.T) {
+	examples := map[string]struct {
+		query        string
+		args         []interface{}
+		style        driver.PlaceholderStyle
+		expected     string
+		expectedArgs []interface{}
+		err          string
+	}{
+		"? to $n": {
+			query:        "age > ? AND name = ? AND data ?? 'key' AND note <> '?' -- ?",
+			args:         []interface{}{18, "John"},
+			style:        driver.Dollar,
+			expected:     "age > $1 AND name = $2 AND data ? 'key' AND note <> '?' -- ?",
+			expectedArgs: []interface{}{18, "John"},
+		},
+
+		"$n to $n": {
+			query:        "age > $1 AND data ? 'key' AND name = $2",
+			args:         []interface{}{18, "John"},
+			style:        driver.Dollar,
+			expected:     "age > $1 AND data ? 'key' AND name = $2",
+			expectedArgs: []interface{}{18, "John"},
+		},
+
+		"$n to ?": {
+			query:        "name = $2 AND (age > $1 OR nickname = $2) AND note <> '$1'",
+			args:         []interface{}{18, "John"},
+			style:        driver.Question,
+			expected:     "name = ? AND (age > ? OR nickname = ?) AND note <> '$1'",
+			expectedArgs: []interface{}{"John", 18, "John"},
+		},
+
+		"$n to ? with escaped ?": {
+			query:        "age > $1 AND data ?? 'key' AND name = $2",
+			args:         []interface{}{18, "John"},
+			style:        driver.Question,
+			expected:     "age > ? AND data ?? 'key' AND name = ?",
+			expectedArgs: []interface{}{18, "John"},
+		},
+
+		"? to ?": {
+			query:        "age > ? AND name = ?",
+			args:         []interface{}{18, "John"},
+			style:        driver.Question,
+			expected:     "age > ? AND name = ?",
+			expectedArgs: []interface{}{18, "John"},
+		},
+
+		"without args": {
+			query:        "data ? 'key'",
+			args:         []interface{}{},
+			style:        driver.Dollar,
+			expected:     "data ? 'key'",
+			expectedArgs: []interface{}{},
+		},
+
+		"with mixed placeholders": {
+			query: "age > $1 AND name = ?",
+			args:  []interface{}{18, "John"},
+			style: driver.Question,
+			err:   "Unable to mix ? and $n placeholders in query 'age > $1 AND name = ?'",
+		},
+
+		"with placeholder out of range": {
+			query: "age > $3",
+			args:  []interface{}{18, "John"},
+			style: driver.Question,
+			err:   "Placeholder $3 is out of range of 2 arguments in query 'age > $3'",
+		},
+	}
+
+	for info, e := range examples {
+		t.Log(info)
+		actual, actualArgs, err := translatePlaceholders(e.query, e.args, e.style)
+		if errRepr(err) != errRepr(nil) || e.err != "" {
+			if errRepr(err) != e.err {
+				t.Errorf("Expected %s to equal %s", errRepr(err), e.err)
+			}
+			continue
+		}
+
+		if actual != e.expected {
+			t.Errorf("Expected %s to equal %s", actual, e.expected)
+		}
+
+		if !reflect.DeepEqual(actualArgs, e.expectedArgs) {
+			t.Errorf("Expected %+v to equal %+v", actualArgs, e.expectedArgs)
+		}
+	}
+}
+
+func TestWherePlaceholders(t *testing.T) {
+	d := fake.NewDriver()
+	d.SetPlaceholderStyle(driver.Question)
+	SetupDriver(d)
+
+	d.RegisterWhere("age > ?", func(record []field.Field, args ...interface{}) (bool, error) {
+		for _, f := range record {
+			if f.DriverName == "age" {
+				return f.Value.(int) > args[0].(int), nil
+			}
+		}
+		return false, nil
+	})
+
+	type Person struct {
+		ModelMetadata `tablename:"people"`
+
+		ID   int    `rebecca:"id" rebecca_primary:"true"`
+		Name string `rebecca:"name"`
+		Age  int    `rebecca:"age"`
+	}
+
+	p1 := &Person{Name: "John", Age: 9}
+	p2 := &Person{Name: "Sarah", Age: 27}
+	for _, p := range []*Person{p1, p2} {
+		if err := Save(p); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	expected := []Person{*p2}
+	for _, query := range []string{"age > $1", "age > ?", "age > :age"} {
+		actual := []Person{}
+		args := []interface{}{18}
+		if query == "age > :age" {
+			args = []interface{}{Named{"age": 18}}
+		}
+
+		if err := Where(&actual, query, args...); err != nil {
+			t.Fatal(err)
+		}
+
+		if !reflect.DeepEqual(actual, expected) {
+			t.Errorf("Expected %+v to equal to %+v for query %s", actual, expected, query)
+		}
+	}
+
+	if err := Exec("UPDATE people SET name = $2 WHERE age > $1", 18, "Jane"); err != nil {
+		t.Fatal(err)
+	}
+
+	actualExec := d.ReceivedExec()
+	expectedExec := fake.ReceivedExec{
+		Query: "UPDATE people SET name = ? WHERE age > ?",
+		Args:  []interface{}{"Jane", 18},
+	}
+	if !reflect.DeepEqual(actualExec, expectedExec) {
+		t.Errorf("Expected driver to receive exec %#v, but got %#v", expectedExec, actualExec)
+	}
+}
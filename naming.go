@@ -0,0 +1,79 @@
+package rebecca
+
+// This file contains thin exported functions and variables related to naming
+// strategies only.
+//
+// For unexported functions see: helpers.go
+
+import (
+	"reflect"
+	"sync"
+)
+
+// NamingStrategy is for mapping names of fields without rebecca tag to
+// column names, and names of model types without tablename tag to table
+// names. Both are optional, fields are mapped to columns of the same name
+// without Column, and table names are not inferred without Table, tablename
+// tag is required then
+type NamingStrategy struct {
+	Column func(name string) string
+	Table  func(name string) string
+}
+
+var (
+	// Identity is for mapping fields to columns of the same name, for
+	// example, CreatedAt to CreatedAt. Table names are not inferred. It is
+	// the default strategy
+	Identity = NamingStrategy{Column: identityName}
+
+	// SnakeCase is for mapping fields to snake case columns, for example,
+	// CreatedAt to created_at, and types to pluralised snake case tables, for
+	// example, BlogPost to blog_posts
+	SnakeCase = NamingStrategy{Column: snakeCase, Table: pluralSnakeCase}
+
+	// CamelCase is for mapping fields to camel case columns, for example,
+	// CreatedAt to createdAt, and types to pluralised snake case tables
+	CamelCase = NamingStrategy{Column: camelCase, Table: pluralSnakeCase}
+)
+
+var (
+	namingStrategy      = Identity
+	modelNamingStrategy = map[reflect.Type]NamingStrategy{}
+	namingStrategyMux   = &sync.RWMutex{}
+)
+
+// CustomNaming is for mapping fields to columns with given function. Table
+// names are not inferred, set Table of returned strategy to PluralSnakeCase
+// to infer them
+func CustomNaming(column func(name string) string) NamingStrategy {
+	return NamingStrategy{Column: column}
+}
+
+// PluralSnakeCase is for inferring table names from names of types as
+// pluralised snake case, for example, BlogPost to blog_posts. Use it as Table
+// of NamingStrategy to opt in to the inference
+func PluralSnakeCase(name string) string {
+	return pluralSnakeCase(name)
+}
+
+// SetNamingStrategy is for configuring naming strategy for all models, that
+// do not have their own one, see SetModelNamingStrategy
+func SetNamingStrategy(strategy NamingStrategy) {
+	namingStrategyMux.Lock()
+	namingStrategy = strategy
+	namingStrategyMux.Unlock()
+
+	uncacheAllMetadata()
+}
+
+// SetModelNamingStrategy is for configuring naming strategy for the model,
+// it takes precedence over the one configured with SetNamingStrategy
+func SetModelNamingStrategy(record interface{}, strategy NamingStrategy) {
+	ty := modelType(record)
+
+	namingStrategyMux.Lock()
+	modelNamingStrategy[ty] = strategy
+	namingStrategyMux.Unlock()
+
+	uncacheMetadata(ty)
+}
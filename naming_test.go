@@ -0,0 +1,163 @@
+package rebecca
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestNamingStrategies(t *testing.T) {
+	examples := map[string]struct {
+		strategy NamingStrategy
+		names    []string
+		expected []string
+	}{
+		"identity": {
+			strategy: Identity,
+			names:    []string{"CreatedAt", "UserID"},
+			expected: []string{"CreatedAt", "UserID"},
+		},
+
+		"snake case": {
+			strategy: SnakeCase,
+			names:    []string{"CreatedAt", "UserID", "ID", "URLPath", "HTTP2Server", "Already_snake"},
+			expected: []string{"created_at", "user_id", "id", "url_path", "http2_server", "already_snake"},
+		},
+
+		"camel case": {
+			strategy: CamelCase,
+			names:    []string{"CreatedAt", "UserID", "ID", "URLPath"},
+			expected: []string{"createdAt", "userID", "id", "urlPath"},
+		},
+
+		"custom": {
+			strategy: CustomNaming(strings.ToUpper),
+			names:    []string{"CreatedAt"},
+			expected: []string{"CREATEDAT"},
+		},
+	}
+
+	for info, e := range examples {
+		t.Log(info)
+		actual := []string{}
+		for _, name := range e.names {
+			actual = append(actual, e.strategy.Column(name))
+		}
+
+		if !reflect.DeepEqual(actual, e.expected) {
+			t.Errorf("Expected %+v to equal %+v", actual, e.expected)
+		}
+	}
+}
+
+func TestPluralSnakeCase(t *testing.T) {
+	examples := map[string]string{
+		"Post":        "posts",
+		"BlogPost":    "blog_posts",
+		"Person":      "people",
+		"Category":    "categories",
+		"Day":         "days",
+		"Address":     "addresses",
+		"Box":         "boxes",
+		"Branch":      "branches",
+		"UserSession": "user_sessions",
+	}
+
+	for name, expected := range examples {
+		t.Log(name)
+		if actual := pluralSnakeCase(name); actual != expected {
+			t.Errorf("Expected %s to equal %s", actual, expected)
+		}
+	}
+}
+
+func TestNamingStrategyMetadata(t *testing.T) {
+	type BlogPost struct {
+		ModelMetadata
+
+		ID        int `rebecca_primary:"true"`
+		CreatedAt string
+		Title     string `rebecca:"heading"`
+	}
+
+	type Comment struct {
+		ModelMetadata `tablename:"remarks"`
+
+		ID     int `rebecca_primary:"true"`
+		PostID int
+	}
+
+	if _, err := getMetadata(&BlogPost{}); errRepr(err) != "Unable to fetch record's metadata - type=github.com/waterlink/rebecca.BlogPost - tablename tag metadata is missing on ModelMetadata" {
+		t.Errorf("Expected identity strategy not to infer table name, but got: %s", errRepr(err))
+	}
+
+	type BlogNote struct {
+		ModelMetadata
+
+		ID int `rebecca_primary:"true"`
+	}
+
+	SetModelNamingStrategy(&BlogNote{}, CustomNaming(strings.ToLower))
+	if _, err := getMetadata(&BlogNote{}); errRepr(err) != "Unable to fetch record's metadata - type=github.com/waterlink/rebecca.BlogNote - tablename tag metadata is missing on ModelMetadata" {
+		t.Errorf("Expected custom strategy not to infer table name, but got: %s", errRepr(err))
+	}
+
+	SetNamingStrategy(SnakeCase)
+	defer SetNamingStrategy(Identity)
+
+	SetModelNamingStrategy(&Comment{}, CamelCase)
+
+	type BlogTag struct {
+		ModelMetadata
+
+		ID    int `rebecca_primary:"true"`
+		Label string
+	}
+
+	SetModelNamingStrategy(&BlogTag{}, NamingStrategy{Table: PluralSnakeCase})
+
+	examples := map[string]struct {
+		record    interface{}
+		tablename string
+		columns   []string
+	}{
+		"global strategy": {
+			record:    &[]BlogPost{},
+			tablename: "blog_posts",
+			columns:   []string{"id", "created_at", "heading"},
+		},
+
+		"model strategy": {
+			record:    &Comment{},
+			tablename: "remarks",
+			columns:   []string{"id", "postID"},
+		},
+
+		"strategy without Column": {
+			record:    &BlogTag{},
+			tablename: "blog_tags",
+			columns:   []string{"ID", "Label"},
+		},
+	}
+
+	for info, e := range examples {
+		t.Log(info)
+		meta, err := getMetadata(e.record)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if meta.tablename != e.tablename {
+			t.Errorf("Expected %s to equal %s", meta.tablename, e.tablename)
+		}
+
+		columns := []string{}
+		for _, f := range meta.fields {
+			columns = append(columns, f.DriverName)
+		}
+
+		if !reflect.DeepEqual(columns, e.columns) {
+			t.Errorf("Expected %+v to equal %+v", columns, e.columns)
+		}
+	}
+}
@@ -0,0 +1,18 @@
+package rebecca
+
+// This file contains thin exported functions related to structured ordering
+// only.
+//
+// For Context see: context.go
+
+import "github.com/waterlink/rebecca/context"
+
+// Asc is for ordering by column in ascending order, see Context.OrderBy
+func Asc(column string) context.Ordering {
+	return context.Ordering{Column: column}
+}
+
+// Desc is for ordering by column in descending order, see Context.OrderBy
+func Desc(column string) context.Ordering {
+	return context.Ordering{Column: column, Descending: true}
+}
@@ -0,0 +1,108 @@
+package rebecca
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/waterlink/rebecca/driver/fake"
+)
+
+func TestOrderBy(t *testing.T) {
+	d := fake.NewDriver()
+	SetupDriver(d)
+
+	type Person struct {
+		ModelMetadata `tablename:"people"`
+
+		ID       int     `rebecca:"id" rebecca_primary:"true"`
+		Name     string  `rebecca:"name"`
+		Age      int     `rebecca:"age"`
+		Nickname *string `rebecca:"nickname"`
+	}
+
+	jo, sa := "Jo", "Sa"
+	p1 := &Person{Name: "John", Age: 27, Nickname: &jo}
+	p2 := &Person{Name: "Sarah", Age: 27, Nickname: &sa}
+	p3 := &Person{Name: "James", Age: 11}
+	people := []*Person{p1, p2, p3}
+
+	for _, p := range people {
+		if err := Save(p); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	examples := map[string]struct {
+		ctx      *Context
+		expected []Person
+		err      string
+	}{
+		"with Asc and Desc": {
+			ctx:      (&Context{}).OrderBy(Asc("age"), Desc("Name")),
+			expected: []Person{*p3, *p2, *p1},
+		},
+
+		"with default nulls placement": {
+			ctx:      (&Context{}).OrderBy(Desc("nickname")),
+			expected: []Person{*p3, *p2, *p1},
+		},
+
+		"with NullsLast": {
+			ctx:      (&Context{}).OrderBy(Desc("nickname").NullsLast()),
+			expected: []Person{*p2, *p1, *p3},
+		},
+
+		"with NullsFirst": {
+			ctx:      (&Context{}).OrderBy(Asc("nickname").NullsFirst()),
+			expected: []Person{*p3, *p1, *p2},
+		},
+
+		"with unknown column": {
+			ctx: (&Context{}).OrderBy(Asc("age; DROP TABLE people")),
+			err: "Unable to order by unknown column age; DROP TABLE people",
+		},
+
+		"with Order": {
+			ctx: (&Context{Order: "age"}).OrderBy(Asc("name")),
+			err: "Unable to combine Order 'age' with OrderBy",
+		},
+	}
+
+	for info, e := range examples {
+		t.Log(info)
+		actual := []Person{}
+		err := e.ctx.All(&actual)
+		if errRepr(err) != errRepr(nil) || e.err != "" {
+			if errRepr(err) != e.err {
+				t.Errorf("Expected %s to equal %s", errRepr(err), e.err)
+			}
+			continue
+		}
+
+		if !reflect.DeepEqual(actual, e.expected) {
+			t.Errorf("Expected %+v to equal to %+v", actual, e.expected)
+		}
+	}
+
+	ctx := (&Context{Limit: 2}).OrderBy(Desc("age"))
+	expected := []Person{*p1, *p2}
+	actual := []Person{}
+	page, err := ctx.Page(&actual, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !reflect.DeepEqual(actual, expected) {
+		t.Errorf("Expected %+v to equal to %+v", actual, expected)
+	}
+
+	expected = []Person{*p3}
+	actual = []Person{}
+	if _, err := ctx.After(page.Next).Page(&actual, ""); err != nil {
+		t.Fatal(err)
+	}
+
+	if !reflect.DeepEqual(actual, expected) {
+		t.Errorf("Expected %+v to equal to %+v", actual, expected)
+	}
+}
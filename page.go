@@ -0,0 +1,61 @@
+package rebecca
+
+// This file contains thin exported functions and types related to pagination
+// only.
+//
+// For unexported functions see: helpers.go
+//
+// For Context see: context.go
+
+import "sync"
+
+var (
+	pageTokenKey    = newPageTokenKey()
+	pageTokenKeyMux = &sync.RWMutex{}
+)
+
+// Page is for storing information about the page of records fetched with
+// Context.Page
+type Page struct {
+	// Token for fetching the next page with Context.After. Empty, when there
+	// is no next page
+	Next string
+
+	// Token for fetching the previous page with Context.Before. Empty, when
+	// there is no previous page
+	Prev string
+
+	// Indicates if there are more records after this page
+	HasNext bool
+
+	// Indicates if there are more records before this page
+	HasPrev bool
+}
+
+// Pagination is for storing information about the page of records fetched
+// with Context.Paginate
+type Pagination struct {
+	// Total amount of matching records
+	Total int
+
+	// Total amount of pages
+	Pages int
+
+	// Current page, starting from 1
+	Page int
+
+	// Maximum amount of records per page
+	PerPage int
+}
+
+// SetPageTokenKey is for configuring secret key, that is used to sign page
+// tokens, so that they can not be tampered with. By default a random key is
+// generated on start, hence tokens do not survive restarts and can not be
+// shared between multiple processes. To avoid that, configure the same key
+// for all of them
+func SetPageTokenKey(key []byte) {
+	pageTokenKeyMux.Lock()
+	defer pageTokenKeyMux.Unlock()
+
+	pageTokenKey = key
+}
@@ -0,0 +1,253 @@
+package rebecca
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/waterlink/rebecca/driver/fake"
+	"github.com/waterlink/rebecca/field"
+)
+
+func TestPage(t *testing.T) {
+	SetupDriver(fake.NewDriver())
+
+	type Person struct {
+		ModelMetadata `tablename:"people"`
+
+		ID   int    `rebecca:"id" rebecca_primary:"true"`
+		Name string `rebecca:"name"`
+		Age  int    `rebecca:"age"`
+	}
+
+	p1 := &Person{Name: "John", Age: 9}
+	p2 := &Person{Name: "Sarah", Age: 27}
+	p3 := &Person{Name: "James", Age: 11}
+	p4 := &Person{Name: "Monika", Age: 11}
+	p5 := &Person{Name: "Peter", Age: 21}
+	people := []*Person{p1, p2, p3, p4, p5}
+
+	for _, p := range people {
+		if err := Save(p); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	ctx := &Context{Order: "age DESC", Limit: 2}
+
+	expected := []Person{*p2, *p5}
+	actual := []Person{}
+	page, err := ctx.Page(&actual, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !reflect.DeepEqual(actual, expected) {
+		t.Errorf("Expected %+v to equal to %+v", actual, expected)
+	}
+
+	if !page.HasNext || page.HasPrev || page.Next == "" || page.Prev != "" {
+		t.Errorf("Expected first page to have only next page, but got: %+v", page)
+	}
+
+	expected = []Person{*p3, *p4}
+	actual = []Person{}
+	second, err := ctx.After(page.Next).Page(&actual, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !reflect.DeepEqual(actual, expected) {
+		t.Errorf("Expected %+v to equal to %+v", actual, expected)
+	}
+
+	if !second.HasNext || !second.HasPrev {
+		t.Errorf("Expected second page to have both next and previous pages, but got: %+v", second)
+	}
+
+	expected = []Person{*p1}
+	actual = []Person{}
+	last, err := ctx.After(second.Next).Page(&actual, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !reflect.DeepEqual(actual, expected) {
+		t.Errorf("Expected %+v to equal to %+v", actual, expected)
+	}
+
+	if last.HasNext || !last.HasPrev || last.Next != "" {
+		t.Errorf("Expected last page to have only previous page, but got: %+v", last)
+	}
+
+	expected = []Person{*p3, *p4}
+	actual = []Person{}
+	prev, err := ctx.Before(last.Prev).Page(&actual, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !reflect.DeepEqual(actual, expected) {
+		t.Errorf("Expected %+v to equal to %+v", actual, expected)
+	}
+
+	if !prev.HasNext || !prev.HasPrev {
+		t.Errorf("Expected second page to have both next and previous pages, but got: %+v", prev)
+	}
+
+	expected = []Person{*p2, *p5}
+	actual = []Person{}
+	first, err := ctx.Before(prev.Prev).Page(&actual, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !reflect.DeepEqual(actual, expected) {
+		t.Errorf("Expected %+v to equal to %+v", actual, expected)
+	}
+
+	if !first.HasNext || first.HasPrev {
+		t.Errorf("Expected first page to have only next page, but got: %+v", first)
+	}
+}
+
+func TestPageInvalidTokens(t *testing.T) {
+	SetupDriver(fake.NewDriver())
+
+	type Person struct {
+		ModelMetadata `tablename:"people"`
+
+		ID   int    `rebecca:"id" rebecca_primary:"true"`
+		Name string `rebecca:"name"`
+		Age  int    `rebecca:"age"`
+	}
+
+	for _, p := range []*Person{{Name: "John", Age: 9}, {Name: "Sarah", Age: 27}} {
+		if err := Save(p); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	ctx := &Context{Order: "age DESC", Limit: 1}
+	page, err := ctx.Page(&[]Person{}, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	parts := strings.Split(page.Next, ".")
+	tampered := parts[0] + "x." + parts[1]
+
+	examples := map[string]struct {
+		ctx *Context
+		err string
+	}{
+		"when token is tampered with": {
+			ctx: ctx.After(tampered),
+			err: "Unable to paginate records - Invalid page token '" + tampered + "'",
+		},
+
+		"when token is garbage": {
+			ctx: ctx.Before("garbage"),
+			err: "Unable to paginate records - Invalid page token 'garbage'",
+		},
+
+		"when token was issued for different order": {
+			ctx: (&Context{Order: "name ASC", Limit: 1}).After(page.Next),
+			err: "Unable to paginate records - Page token '" + page.Next + "' was issued for different model or order",
+		},
+
+		"when order is not a list of columns": {
+			ctx: &Context{Order: "lower(name) DESC NULLS LAST"},
+			err: "Unable to paginate records - Order is required to be a list of columns with optional ASC or DESC, but got: 'lower(name) DESC NULLS LAST'",
+		},
+
+		"when order column is unknown": {
+			ctx: &Context{Order: "height"},
+			err: "Unable to paginate records - Order column height is not a field of the model",
+		},
+	}
+
+	for info, e := range examples {
+		t.Log(info)
+		_, err := e.ctx.Page(&[]Person{}, "")
+		if errRepr(err) != e.err {
+			t.Errorf("Expected %s to equal %s", errRepr(err), e.err)
+		}
+	}
+}
+
+func TestPaginate(t *testing.T) {
+	d := fake.NewDriver()
+	SetupDriver(d)
+
+	d.RegisterWhere("age > $1", func(record []field.Field, args ...interface{}) (bool, error) {
+		for _, f := range record {
+			if f.DriverName == "age" {
+				return f.Value.(int) > args[0].(int), nil
+			}
+		}
+
+		return false, fmt.Errorf("record %+v does not have age field", record)
+	})
+
+	type Person struct {
+		ModelMetadata `tablename:"people"`
+
+		ID   int    `rebecca:"id" rebecca_primary:"true"`
+		Name string `rebecca:"name"`
+		Age  int    `rebecca:"age"`
+	}
+
+	p1 := &Person{Name: "John", Age: 9}
+	p2 := &Person{Name: "Sarah", Age: 27}
+	p3 := &Person{Name: "James", Age: 11}
+	p4 := &Person{Name: "Monika", Age: 12}
+	p5 := &Person{Name: "Peter", Age: 21}
+	people := []*Person{p1, p2, p3, p4, p5}
+
+	for _, p := range people {
+		if err := Save(p); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	expected := []Person{*p4, *p5}
+	actual := []Person{}
+	pagination, err := Paginate(&actual, 2, 2, "age > $1", 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !reflect.DeepEqual(actual, expected) {
+		t.Errorf("Expected %+v to equal to %+v", actual, expected)
+	}
+
+	expectedPagination := &Pagination{Total: 4, Pages: 2, Page: 2, PerPage: 2}
+	if !reflect.DeepEqual(pagination, expectedPagination) {
+		t.Errorf("Expected %+v to equal to %+v", pagination, expectedPagination)
+	}
+
+	expected = []Person{*p4, *p3}
+	actual = []Person{}
+	ctx := &Context{Order: "age DESC"}
+	pagination, err = ctx.Paginate(&actual, 2, 2, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !reflect.DeepEqual(actual, expected) {
+		t.Errorf("Expected %+v to equal to %+v", actual, expected)
+	}
+
+	expectedPagination = &Pagination{Total: 5, Pages: 3, Page: 2, PerPage: 2}
+	if !reflect.DeepEqual(pagination, expectedPagination) {
+		t.Errorf("Expected %+v to equal to %+v", pagination, expectedPagination)
+	}
+
+	_, err = Paginate(&actual, 0, 2, "")
+	expectedErr := "Page is required to be positive, but got: 0"
+	if errRepr(err) != expectedErr {
+		t.Errorf("Expected %s to equal %s", errRepr(err), expectedErr)
+	}
+}
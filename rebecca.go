@@ -44,6 +44,15 @@ import "github.com/waterlink/rebecca/driver"
 //
 // For Context see: context.go
 
+// Named is for passing named parameters to queries. Named parameters are
+// referenced in queries as :name, for example:
+//
+//    rebecca.Where(&people, "age > :min_age", rebecca.Named{"min_age": 18})
+//
+// Instead of Named a struct with rebecca tags can be passed, its fields are
+// referenced by their tags then
+type Named map[string]interface{}
+
 // SetupDriver is for configuring database driver
 func SetupDriver(d driver.Driver) {
 	driver.SetupDriver(d)
@@ -51,7 +60,7 @@ func SetupDriver(d driver.Driver) {
 
 // Get is for fetching one record
 func Get(record interface{}, ID interface{}) error {
-	return get(nil, ID, record)
+	return get(&Context{}, ID, record)
 }
 
 // All is for fetching all records
@@ -61,25 +70,72 @@ func All(records interface{}) error {
 }
 
 // Where is for fetching specific records
-func Where(records interface{}, where string, args ...interface{}) error {
+func Where(records interface{}, where interface{}, args ...interface{}) error {
 	ctx := &Context{}
 	return ctx.Where(records, where, args...)
 }
 
 // First is for fetching only one specific record
-func First(record interface{}, where string, args ...interface{}) error {
+func First(record interface{}, where interface{}, args ...interface{}) error {
 	ctx := &Context{}
 	return ctx.First(record, where, args...)
 }
 
+// WhereExample is for fetching records, that are equal to the example in all
+// its non-zero fields and in fields listed in include
+func WhereExample(records interface{}, example interface{}, include ...string) error {
+	ctx := &Context{}
+	return ctx.WhereExample(records, example, include...)
+}
+
+// FirstExample is for fetching only first record, that is equal to the
+// example in all its non-zero fields and in fields listed in include
+func FirstExample(record interface{}, example interface{}, include ...string) error {
+	ctx := &Context{}
+	return ctx.FirstExample(record, example, include...)
+}
+
+// Iterate is for fetching specific records lazily one by one
+func Iterate(record interface{}, where interface{}, args ...interface{}) (*Iterator, error) {
+	ctx := &Context{}
+	return ctx.Iterate(record, where, args...)
+}
+
+// Each is for calling fn for each specific record without loading all of
+// them into memory at once
+func Each(record interface{}, where interface{}, args []interface{}, fn interface{}) error {
+	ctx := &Context{}
+	return ctx.Each(record, where, args, fn)
+}
+
+// FindInBatches is for walking through all records in batches of given size,
+// ordered by primary key
+func FindInBatches(records interface{}, size int, fn interface{}) error {
+	ctx := &Context{}
+	return ctx.FindInBatches(records, size, fn)
+}
+
+// Count is for counting specific records
+func Count(record interface{}, where interface{}, args ...interface{}) (int, error) {
+	ctx := &Context{}
+	return ctx.Count(record, where, args...)
+}
+
+// Paginate is for fetching given page of specific records together with the
+// total count of them
+func Paginate(records interface{}, page, perPage int, where interface{}, args ...interface{}) (*Pagination, error) {
+	ctx := &Context{}
+	return ctx.Paginate(records, page, perPage, where, args...)
+}
+
 // Save is for saving one record (either creating or updating)
 func Save(record interface{}) error {
-	return save(nil, record)
+	return save(&Context{}, record)
 }
 
 // Remove is for removing the record
 func Remove(record interface{}) error {
-	return remove(nil, record)
+	return remove(&Context{}, record)
 }
 
 // Exec is for executing arbitrary query and discarding its result
@@ -445,3 +445,300 @@ func errRepr(err error) string {
 	}
 	return err.Error()
 }
+
+func TestFindInBatches(t *testing.T) {
+	SetupDriver(fake.NewDriver())
+
+	type Person struct {
+		ModelMetadata `tablename:"people"`
+
+		ID   int    `rebecca:"id" rebecca_primary:"true"`
+		Name string `rebecca:"name"`
+		Age  int    `rebecca:"age"`
+	}
+
+	p1 := &Person{Name: "John", Age: 9}
+	p2 := &Person{Name: "Sarah", Age: 27}
+	p3 := &Person{Name: "James", Age: 11}
+	p4 := &Person{Name: "Monika", Age: 12}
+	p5 := &Person{Name: "Peter", Age: 21}
+	people := []*Person{p1, p2, p3, p4, p5}
+
+	for _, p := range people {
+		if err := Save(p); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	expected := [][]Person{{*p1, *p2}, {*p3, *p4}, {*p5}}
+	actual := [][]Person{}
+	err := FindInBatches(&[]Person{}, 2, func(batch []Person) error {
+		actual = append(actual, batch)
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !reflect.DeepEqual(actual, expected) {
+		t.Errorf("Expected %+v to equal to %+v", actual, expected)
+	}
+
+	stop := errors.New("stop here")
+	expected = [][]Person{{*p1, *p2, *p3}}
+	actual = [][]Person{}
+	err = FindInBatches(&[]Person{}, 3, func(batch []Person) error {
+		actual = append(actual, batch)
+		return stop
+	})
+	if err != stop {
+		t.Errorf("Expected FindInBatches to return %s, but got: %s", stop, err)
+	}
+
+	if !reflect.DeepEqual(actual, expected) {
+		t.Errorf("Expected %+v to equal to %+v", actual, expected)
+	}
+
+	tx, err := Begin()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer tx.Rollback()
+
+	p6 := &Person{Name: "Bruce", Age: 33}
+	if err := tx.Save(p6); err != nil {
+		t.Fatal(err)
+	}
+
+	expected = [][]Person{{*p1, *p2, *p3, *p4}, {*p5, *p6}}
+	actual = [][]Person{}
+	err = tx.FindInBatches(&[]Person{}, 4, func(batch []Person) error {
+		actual = append(actual, batch)
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !reflect.DeepEqual(actual, expected) {
+		t.Errorf("Expected %+v to equal to %+v", actual, expected)
+	}
+
+	expected = [][]Person{{*p1, *p2, *p3}, {*p4, *p5}}
+	actual = [][]Person{}
+	err = (&Context{}).OrderBy(Desc("name")).FindInBatches(&[]Person{}, 3, func(batch []Person) error {
+		actual = append(actual, batch)
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !reflect.DeepEqual(actual, expected) {
+		t.Errorf("Expected %+v to equal to %+v", actual, expected)
+	}
+
+	err = FindInBatches(&[]Person{}, 0, func(batch []Person) error { return nil })
+	expectedErr := "Batch size is required to be positive, but got: 0"
+	if errRepr(err) != expectedErr {
+		t.Errorf("Expected %s to equal %s", errRepr(err), expectedErr)
+	}
+}
+
+func TestCount(t *testing.T) {
+	d := fake.NewDriver()
+	SetupDriver(d)
+
+	d.RegisterWhere("age < $1", func(record []field.Field, args ...interface{}) (bool, error) {
+		for _, f := range record {
+			if f.DriverName == "age" {
+				return f.Value.(int) < args[0].(int), nil
+			}
+		}
+
+		return false, fmt.Errorf("record %+v does not have age field", record)
+	})
+
+	type Person struct {
+		ModelMetadata `tablename:"people"`
+
+		ID   int    `rebecca:"id" rebecca_primary:"true"`
+		Name string `rebecca:"name"`
+		Age  int    `rebecca:"age"`
+	}
+
+	p1 := &Person{Name: "John", Age: 9}
+	p2 := &Person{Name: "Sarah", Age: 27}
+	p3 := &Person{Name: "James", Age: 11}
+	people := []*Person{p1, p2, p3}
+
+	for _, p := range people {
+		if err := Save(p); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	actual, err := Count(&Person{}, "age < $1", 12)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if actual != 2 {
+		t.Errorf("Expected %d to equal to %d", actual, 2)
+	}
+
+	ctx := &Context{Limit: 1}
+	actual, err = ctx.Count(&Person{}, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if actual != 3 {
+		t.Errorf("Expected %d to equal to %d", actual, 3)
+	}
+}
+
+func TestSelectAndDistinct(t *testing.T) {
+	d := fake.NewDriver()
+	SetupDriver(d)
+
+	type Person struct {
+		ModelMetadata `tablename:"people"`
+
+		ID   int    `rebecca:"id" rebecca_primary:"true"`
+		Name string `rebecca:"name"`
+		Age  int    `rebecca:"age"`
+	}
+
+	p1 := &Person{Name: "John", Age: 9}
+	p2 := &Person{Name: "Sarah", Age: 27}
+	p3 := &Person{Name: "John", Age: 11}
+	people := []*Person{p1, p2, p3}
+
+	for _, p := range people {
+		if err := Save(p); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	examples := map[string]struct {
+		ctx      *Context
+		expected []Person
+		err      string
+	}{
+		"with Select": {
+			ctx:      &Context{Select: []string{"Name", "age"}, Order: "age"},
+			expected: []Person{{Name: "John", Age: 9}, {Name: "John", Age: 11}, {Name: "Sarah", Age: 27}},
+		},
+
+		"with Select and Distinct": {
+			ctx:      &Context{Select: []string{"name"}, Distinct: true, Order: "name"},
+			expected: []Person{{Name: "John"}, {Name: "Sarah"}},
+		},
+
+		"with DistinctOn": {
+			ctx:      &Context{DistinctOn: []string{"name"}, Order: "name, age DESC"},
+			expected: []Person{*p3, *p2},
+		},
+
+		"with unknown field in Select": {
+			ctx: &Context{Select: []string{"Nickname"}},
+			err: "Unable to select unknown field Nickname",
+		},
+	}
+
+	for info, e := range examples {
+		t.Log(info)
+		actual := []Person{}
+		err := e.ctx.All(&actual)
+		if errRepr(err) != errRepr(nil) || e.err != "" {
+			if errRepr(err) != e.err {
+				t.Errorf("Expected %s to equal %s", errRepr(err), e.err)
+			}
+			continue
+		}
+
+		if !reflect.DeepEqual(actual, e.expected) {
+			t.Errorf("Expected %+v to equal to %+v", actual, e.expected)
+		}
+	}
+
+	byID := map[int]Person{}
+	if err := (&Context{Select: []string{"name"}}).All(byID); err != nil {
+		t.Fatal(err)
+	}
+
+	expectedByID := map[int]Person{
+		p1.ID: {ID: p1.ID, Name: "John"},
+		p2.ID: {ID: p2.ID, Name: "Sarah"},
+		p3.ID: {ID: p3.ID, Name: "John"},
+	}
+	if !reflect.DeepEqual(byID, expectedByID) {
+		t.Errorf("Expected %+v to equal to %+v", byID, expectedByID)
+	}
+
+	ctx := &Context{Select: []string{"name"}, Order: "age DESC", Limit: 2}
+	first := []Person{}
+	page, err := ctx.Page(&first, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	second := []Person{}
+	if _, err := ctx.After(page.Next).Page(&second, ""); err != nil {
+		t.Fatal(err)
+	}
+
+	expectedSecond := []Person{*p1}
+	if !reflect.DeepEqual(second, expectedSecond) {
+		t.Errorf("Expected %+v to equal to %+v", second, expectedSecond)
+	}
+}
+
+func TestHaving(t *testing.T) {
+	d := fake.NewDriver()
+	SetupDriver(d)
+
+	d.RegisterHaving("count(*) > $1", func(group [][]field.Field, args ...interface{}) (bool, error) {
+		return len(group) > args[0].(int), nil
+	})
+
+	type Person struct {
+		ModelMetadata `tablename:"people"`
+
+		ID   int    `rebecca:"id" rebecca_primary:"true"`
+		Name string `rebecca:"name"`
+		Age  int    `rebecca:"age"`
+	}
+
+	p1 := &Person{Name: "John", Age: 9}
+	p2 := &Person{Name: "Sarah", Age: 27}
+	p3 := &Person{Name: "John", Age: 11}
+	people := []*Person{p1, p2, p3}
+
+	for _, p := range people {
+		if err := Save(p); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	ctx := &Context{Select: []string{"name"}, Group: "name", Having: "count(*) > ?", HavingArgs: []interface{}{1}}
+	expected := []Person{{Name: "John"}}
+	actual := []Person{}
+	if err := ctx.All(&actual); err != nil {
+		t.Fatal(err)
+	}
+
+	if !reflect.DeepEqual(actual, expected) {
+		t.Errorf("Expected %+v to equal to %+v", actual, expected)
+	}
+
+	count, err := ctx.Count(&Person{}, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if count != 1 {
+		t.Errorf("Expected %d to equal to %d", count, 1)
+	}
+}
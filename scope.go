@@ -0,0 +1,59 @@
+package rebecca
+
+// This file contains thin exported functions related to scopes only.
+//
+// For unexported functions see: helpers.go
+
+import (
+	"fmt"
+	"reflect"
+	"sync"
+
+	"github.com/waterlink/rebecca/condition"
+)
+
+var (
+	definedScopes    = map[reflect.Type]map[string]condition.Condition{}
+	defaultScopes    = map[reflect.Type][]condition.Condition{}
+	definedScopesMux = &sync.RWMutex{}
+)
+
+// DefineScope is for defining named condition for the model, so that it can
+// be applied by name with Context.Scope, for example:
+//
+//	rebecca.DefineScope(&Person{}, "adults", rebecca.Gt("age", 17))
+//	ctx := (&rebecca.Context{}).Scope("adults")
+func DefineScope(record interface{}, name string, cond condition.Condition) error {
+	meta, err := getMetadata(record)
+	if err != nil {
+		return fmt.Errorf("Unable to define scope %s - %s", name, err)
+	}
+
+	definedScopesMux.Lock()
+	defer definedScopesMux.Unlock()
+
+	if definedScopes[meta.ty] == nil {
+		definedScopes[meta.ty] = map[string]condition.Condition{}
+	}
+	definedScopes[meta.ty][name] = cond
+	return nil
+}
+
+// DefineDefaultScope is for defining condition, that is applied to every
+// query of the model: All, Where, First, Count, Get and friends, for example:
+//
+//	rebecca.DefineDefaultScope(&Person{}, rebecca.Eq("active", true))
+//
+// Use Context.Unscoped to disable default scopes for specific query
+func DefineDefaultScope(record interface{}, cond condition.Condition) error {
+	meta, err := getMetadata(record)
+	if err != nil {
+		return fmt.Errorf("Unable to define default scope - %s", err)
+	}
+
+	definedScopesMux.Lock()
+	defer definedScopesMux.Unlock()
+
+	defaultScopes[meta.ty] = append(defaultScopes[meta.ty], cond)
+	return nil
+}
@@ -0,0 +1,156 @@
+package rebecca
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/waterlink/rebecca/driver/fake"
+)
+
+func TestScopes(t *testing.T) {
+	SetupDriver(fake.NewDriver())
+
+	type Member struct {
+		ModelMetadata `tablename:"members"`
+
+		ID     int    `rebecca:"id" rebecca_primary:"true"`
+		Name   string `rebecca:"name"`
+		Age    int    `rebecca:"age"`
+		Active bool   `rebecca:"active"`
+	}
+
+	john := &Member{Name: "John", Age: 34, Active: true}
+	jane := &Member{Name: "Jane", Age: 17, Active: true}
+	bob := &Member{Name: "Bob", Age: 45, Active: false}
+	for _, m := range []*Member{john, jane, bob} {
+		if err := Save(m); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := DefineScope(&Member{}, "adults", Gt("age", 17)); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := DefineScope(&Member{}, "named_j", Like("name", "J%")); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := DefineDefaultScope(&Member{}, Eq("active", true)); err != nil {
+		t.Fatal(err)
+	}
+
+	examples := map[string]struct {
+		action   func() (interface{}, error)
+		expected interface{}
+		err      string
+	}{
+		"All with default scope": {
+			action: func() (interface{}, error) {
+				members := []Member{}
+				err := All(&members)
+				return members, err
+			},
+			expected: []Member{*john, *jane},
+		},
+
+		"All unscoped": {
+			action: func() (interface{}, error) {
+				members := []Member{}
+				err := (&Context{}).Unscoped().All(&members)
+				return members, err
+			},
+			expected: []Member{*john, *jane, *bob},
+		},
+
+		"Where with named scope": {
+			action: func() (interface{}, error) {
+				members := []Member{}
+				err := (&Context{}).Scope("adults").Where(&members, Like("name", "%o%"))
+				return members, err
+			},
+			expected: []Member{*john},
+		},
+
+		"Where with multiple scopes unscoped": {
+			action: func() (interface{}, error) {
+				members := []Member{}
+				err := (&Context{}).Scope("adults").Unscoped().Where(&members, "")
+				return members, err
+			},
+			expected: []Member{*john, *bob},
+		},
+
+		"Count with scopes": {
+			action: func() (interface{}, error) {
+				return (&Context{}).Scope("adults", "named_j").Count(&Member{}, "")
+			},
+			expected: 1,
+		},
+
+		"Builder with scopes": {
+			action: func() (interface{}, error) {
+				members := []Member{}
+				err := Q().Scope("named_j").Order("age").All(&members)
+				return members, err
+			},
+			expected: []Member{*jane, *john},
+		},
+
+		"Get with default scope": {
+			action: func() (interface{}, error) {
+				m := &Member{}
+				err := Get(m, bob.ID)
+				return m, err
+			},
+			err: "Unable to find record - Unable to fetch specific records - Record not found with where query ''",
+		},
+
+		"Get unscoped": {
+			action: func() (interface{}, error) {
+				return Find[Member]((&Context{}).Unscoped(), bob.ID)
+			},
+			expected: bob,
+		},
+
+		"other model of the same table": {
+			action: func() (interface{}, error) {
+				type MemberName struct {
+					ModelMetadata `tablename:"members"`
+
+					ID   int    `rebecca:"id" rebecca_primary:"true"`
+					Name string `rebecca:"name"`
+				}
+
+				names := []MemberName{}
+				err := All(&names)
+				return len(names), err
+			},
+			expected: 3,
+		},
+
+		"unknown scope": {
+			action: func() (interface{}, error) {
+				members := []Member{}
+				err := (&Context{}).Scope("children").All(&members)
+				return members, err
+			},
+			err: "Unable to find scope children defined for members",
+		},
+	}
+
+	for info, e := range examples {
+		t.Log(info)
+		actual, err := e.action()
+		if errRepr(err) != errRepr(nil) || e.err != "" {
+			if errRepr(err) != e.err {
+				t.Errorf("Expected %s to equal %s", errRepr(err), e.err)
+			}
+			continue
+		}
+
+		if !reflect.DeepEqual(actual, e.expected) {
+			t.Errorf("Expected %+v to equal %+v", actual, e.expected)
+		}
+	}
+}
@@ -0,0 +1,167 @@
+package rebecca
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/waterlink/rebecca/driver/fake"
+)
+
+type partitionedEvent struct {
+	ModelMetadata
+
+	ID        int       `rebecca:"id" rebecca_primary:"true"`
+	Name      string    `rebecca:"name"`
+	CreatedAt time.Time `rebecca:"created_at"`
+}
+
+func (e *partitionedEvent) TableName() string {
+	if e.CreatedAt.IsZero() {
+		return "events"
+	}
+	return fmt.Sprintf("events_%s", e.CreatedAt.Format("2006_01"))
+}
+
+type archivedNote struct {
+	ModelMetadata `tablename:"notes" schema:"archive"`
+
+	ID   int    `rebecca:"id" rebecca_primary:"true"`
+	Text string `rebecca:"text"`
+}
+
+func (n *archivedNote) TableNameFor(ctx *Context, record interface{}) string {
+	if _, ok := record.(*[]archivedNote); ok && ctx.Group == "" {
+		return "notes_list"
+	}
+	return "notes"
+}
+
+func TestTableName(t *testing.T) {
+	d := fake.NewDriver()
+	SetupDriver(d)
+
+	october := &partitionedEvent{Name: "signup", CreatedAt: time.Date(2026, 10, 3, 0, 0, 0, 0, time.UTC)}
+	november := &partitionedEvent{Name: "login", CreatedAt: time.Date(2026, 11, 5, 0, 0, 0, 0, time.UTC)}
+	for _, e := range []*partitionedEvent{october, november} {
+		if err := Save(e); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	fetched := &partitionedEvent{CreatedAt: october.CreatedAt}
+	if err := Get(fetched, october.ID); err != nil {
+		t.Fatal(err)
+	}
+	if fetched.Name != "signup" {
+		t.Errorf("Expected %s to equal signup", fetched.Name)
+	}
+
+	missing := &partitionedEvent{CreatedAt: november.CreatedAt}
+	if err := Get(missing, october.ID); err == nil {
+		t.Errorf("Expected record to be missing in events_2026_11, but got: %+v", missing)
+	}
+
+	examples := map[string]struct {
+		tablename string
+		expected  []string
+	}{
+		"october partition": {
+			tablename: "events_2026_10",
+			expected:  []string{"signup"},
+		},
+
+		"november partition": {
+			tablename: "events_2026_11",
+			expected:  []string{"login"},
+		},
+
+		"tag-less table of zero value": {
+			tablename: "events",
+			expected:  []string{},
+		},
+	}
+
+	for info, e := range examples {
+		t.Log(info)
+		fieldss, err := d.All(e.tablename, nil, &Context{})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		names := []string{}
+		for _, fields := range fieldss {
+			for _, f := range fields {
+				if f.Name == "Name" {
+					names = append(names, f.Value.(string))
+				}
+			}
+		}
+
+		if !reflect.DeepEqual(names, e.expected) {
+			t.Errorf("Expected %+v to equal %+v", names, e.expected)
+		}
+	}
+
+	events := []partitionedEvent{}
+	if err := All(&events); err != nil {
+		t.Fatal(err)
+	}
+	if len(events) != 0 {
+		t.Errorf("Expected slice of zero value models to be fetched from events table, but got: %+v", events)
+	}
+
+	if err := Remove(october); err != nil {
+		t.Fatal(err)
+	}
+	if err := Get(&partitionedEvent{CreatedAt: october.CreatedAt}, october.ID); err == nil {
+		t.Errorf("Expected record to be removed from events_2026_10")
+	}
+}
+
+func TestTableNameFor(t *testing.T) {
+	examples := map[string]struct {
+		ctx      *Context
+		record   interface{}
+		expected string
+	}{
+		"single record": {
+			ctx:      &Context{},
+			record:   &archivedNote{},
+			expected: "archive.notes",
+		},
+
+		"slice of records": {
+			ctx:      &Context{},
+			record:   &[]archivedNote{},
+			expected: "archive.notes_list",
+		},
+
+		"slice of records with context": {
+			ctx:      &Context{Group: "text"},
+			record:   &[]archivedNote{},
+			expected: "archive.notes",
+		},
+
+		"model without table namer": {
+			ctx: &Context{},
+			record: &struct {
+				ModelMetadata `tablename:"people"`
+			}{},
+			expected: "people",
+		},
+	}
+
+	for info, e := range examples {
+		t.Log(info)
+		meta, err := metadataFor(e.ctx, e.record)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if meta.tablename != e.expected {
+			t.Errorf("Expected %s to equal %s", meta.tablename, e.expected)
+		}
+	}
+}
@@ -0,0 +1,106 @@
+package rebecca
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/waterlink/rebecca/driver/fake"
+)
+
+type targetPerson struct {
+	ModelMetadata `tablename:"people"`
+
+	ID   int    `rebecca:"id" rebecca_primary:"true"`
+	Name string `rebecca:"name"`
+}
+
+type targetPeople []targetPerson
+
+func TestQueryTargets(t *testing.T) {
+	SetupDriver(fake.NewDriver())
+
+	john := &targetPerson{Name: "John"}
+	jane := &targetPerson{Name: "Jane"}
+	for _, p := range []*targetPerson{john, jane} {
+		if err := Save(p); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	nilMap := map[int]*targetPerson(nil)
+
+	examples := map[string]struct {
+		records  interface{}
+		expected interface{}
+		err      string
+	}{
+		"slice of models": {
+			records:  &[]targetPerson{},
+			expected: &[]targetPerson{*john, *jane},
+		},
+
+		"slice of pointers": {
+			records:  &[]*targetPerson{},
+			expected: &[]*targetPerson{john, jane},
+		},
+
+		"named slice": {
+			records:  &targetPeople{},
+			expected: &targetPeople{*john, *jane},
+		},
+
+		"map of pointers": {
+			records:  map[int]*targetPerson{},
+			expected: map[int]*targetPerson{john.ID: john, jane.ID: jane},
+		},
+
+		"pointer to map of models": {
+			records:  &map[int64]targetPerson{},
+			expected: &map[int64]targetPerson{int64(john.ID): *john, int64(jane.ID): *jane},
+		},
+
+		"pointer to nil map": {
+			records:  &nilMap,
+			expected: &map[int]*targetPerson{john.ID: john, jane.ID: jane},
+		},
+
+		"nil map": {
+			records: map[int]*targetPerson(nil),
+			err:     "Unable to fetch all records - Records are required to be a pointer to map or non-nil map, but got: map[int]*rebecca.targetPerson",
+		},
+
+		"map with incompatible key": {
+			records: map[string]*targetPerson{},
+			err:     "Unable to fetch all records - Unable to use primary field of type int as a key of type string",
+		},
+
+		"slice without pointer": {
+			records: []targetPerson{},
+			err:     "Unable to fetch all records - Records are required to be a pointer to slice, but got: []rebecca.targetPerson",
+		},
+	}
+
+	for info, e := range examples {
+		t.Log(info)
+		err := All(e.records)
+		if errRepr(err) != errRepr(nil) || e.err != "" {
+			if errRepr(err) != e.err {
+				t.Errorf("Expected %s to equal %s", errRepr(err), e.err)
+			}
+			continue
+		}
+
+		if !reflect.DeepEqual(e.records, e.expected) {
+			t.Errorf("Expected %+v to equal %+v", e.records, e.expected)
+		}
+	}
+
+	people := []*targetPerson{}
+	if err := Where(&people, Eq("name", "Jane")); err != nil {
+		t.Fatal(err)
+	}
+
+	if !reflect.DeepEqual(people, []*targetPerson{jane}) {
+		t.Errorf("Expected %+v to equal %+v", people, []*targetPerson{jane})
+	}
+}
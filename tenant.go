@@ -0,0 +1,58 @@
+package rebecca
+
+// This file contains thin exported functions related to multi-tenancy only.
+//
+// For Context see: context.go
+
+import (
+	stdcontext "context"
+	"fmt"
+
+	"github.com/waterlink/rebecca/driver"
+)
+
+type tenantKey struct{}
+
+// WithTenant is for carrying tenant in standard library context, for
+// example, from authentication middleware to the handler, where it is
+// applied with Context.WithContext
+func WithTenant(ctx stdcontext.Context, tenant interface{}) stdcontext.Context {
+	return stdcontext.WithValue(ctx, tenantKey{}, tenant)
+}
+
+// TenantFrom is for fetching tenant carried by standard library context
+func TenantFrom(ctx stdcontext.Context) (interface{}, bool) {
+	tenant := ctx.Value(tenantKey{})
+	return tenant, tenant != nil
+}
+
+// CreateTenant is for creating the storage of the tenant with tables of
+// given models, for example, the schema of the tenant with pg driver
+// configured with pg.TenantSchema. It requires the driver, that keeps
+// records of each tenant separately
+func CreateTenant(tenant interface{}, records ...interface{}) error {
+	ctx := (&Context{}).ForTenant(tenant)
+
+	tablenames := []string{}
+	for _, record := range records {
+		meta, err := metadataFor(ctx, record)
+		if err != nil {
+			return fmt.Errorf("Unable to create tenant %v - %s", tenant, err)
+		}
+		tablenames = append(tablenames, meta.tablename)
+	}
+
+	d, lock := driver.Get()
+	defer lock.Unlock()
+
+	tenantDriver, ok := d.(driver.TenantDriver)
+	if !ok {
+		return fmt.Errorf("Unable to create tenant %v - driver does not keep records of tenants separately", tenant)
+	}
+
+	if err := tenantDriver.CreateTenant(tenant, tablenames); err != nil {
+		return fmt.Errorf("Unable to create tenant %v - %s", tenant, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,302 @@
+package rebecca
+
+import (
+	stdcontext "context"
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/waterlink/rebecca/driver"
+	"github.com/waterlink/rebecca/driver/fake"
+)
+
+func TestTenant(t *testing.T) {
+	SetupDriver(fake.NewDriver())
+
+	type Document struct {
+		ModelMetadata `tablename:"documents"`
+
+		ID       int    `rebecca:"id" rebecca_primary:"true"`
+		TenantID int64  `rebecca:"tenant_id" rebecca_tenant:"true"`
+		Title    string `rebecca:"title"`
+	}
+
+	acme := (&Context{}).ForTenant(1)
+	globex := (&Context{}).WithContext(WithTenant(stdcontext.Background(), int64(2)))
+
+	report := &Document{Title: "Report"}
+	if err := acme.Save(report); err != nil {
+		t.Fatal(err)
+	}
+	if report.TenantID != 1 {
+		t.Errorf("Expected tenant to be assigned on create, but got: %d", report.TenantID)
+	}
+
+	memo := &Document{Title: "Memo"}
+	if err := globex.Save(memo); err != nil {
+		t.Fatal(err)
+	}
+
+	examples := map[string]struct {
+		action   func() (interface{}, error)
+		expected interface{}
+		err      string
+	}{
+		"All of tenant": {
+			action: func() (interface{}, error) {
+				docs := []Document{}
+				err := acme.All(&docs)
+				return docs, err
+			},
+			expected: []Document{*report},
+		},
+
+		"Where of tenant ignores Unscoped": {
+			action: func() (interface{}, error) {
+				docs := []Document{}
+				err := globex.Unscoped().Where(&docs, Like("title", "%"))
+				return docs, err
+			},
+			expected: []Document{*memo},
+		},
+
+		"Count of tenant": {
+			action: func() (interface{}, error) {
+				return globex.Count(&Document{}, "")
+			},
+			expected: 1,
+		},
+
+		"Get of tenant": {
+			action: func() (interface{}, error) {
+				doc := &Document{}
+				err := acme.Get(doc, report.ID)
+				return doc, err
+			},
+			expected: report,
+		},
+
+		"Get of another tenant": {
+			action: func() (interface{}, error) {
+				return nil, acme.Get(&Document{}, memo.ID)
+			},
+			err: "Unable to find record - Unable to fetch specific records - Record not found with where query ''",
+		},
+
+		"All without tenant": {
+			action: func() (interface{}, error) {
+				return nil, All(&[]Document{})
+			},
+			err: "Unable to query records - tenant is required for tenant-scoped model, use Context.ForTenant",
+		},
+
+		"Save without tenant": {
+			action: func() (interface{}, error) {
+				return nil, Save(&Document{Title: "Draft"})
+			},
+			err: "Unable to save record &{ModelMetadata:{} ID:0 TenantID:0 Title:Draft} - tenant is required for tenant-scoped model, use Context.ForTenant",
+		},
+
+		"Save record of another tenant": {
+			action: func() (interface{}, error) {
+				return nil, acme.Save(&Document{ID: memo.ID, TenantID: 2, Title: "Stolen"})
+			},
+			err: "Unable to save record &{ModelMetadata:{} ID:2 TenantID:2 Title:Stolen} - it belongs to tenant 2, not to 1",
+		},
+
+		"Save record of another tenant without tenant field": {
+			action: func() (interface{}, error) {
+				return nil, acme.Save(&Document{ID: memo.ID, Title: "Stolen"})
+			},
+			err: "Unable to update record &{ModelMetadata:{} ID:2 TenantID:1 Title:Stolen} - it does not belong to tenant 1",
+		},
+
+		"Remove record of another tenant": {
+			action: func() (interface{}, error) {
+				return nil, globex.Remove(&Document{ID: report.ID})
+			},
+			err: "Unable to remove record &{ModelMetadata:{} ID:1 TenantID:2 Title:} - it does not belong to tenant 2",
+		},
+
+		"Remove without tenant": {
+			action: func() (interface{}, error) {
+				return nil, Remove(report)
+			},
+			err: "Unable to remove record &{ModelMetadata:{} ID:1 TenantID:1 Title:Report} - tenant is required for tenant-scoped model, use Context.ForTenant",
+		},
+
+		"tenant of incompatible type": {
+			action: func() (interface{}, error) {
+				return nil, (&Context{}).ForTenant("acme").All(&[]Document{})
+			},
+			err: "Unable to query records - Unable to use tenant of type string for field TenantID of type int64",
+		},
+	}
+
+	for info, e := range examples {
+		t.Log(info)
+		actual, err := e.action()
+		if errRepr(err) != errRepr(nil) || e.err != "" {
+			if errRepr(err) != e.err {
+				t.Errorf("Expected %s to equal %s", errRepr(err), e.err)
+			}
+			continue
+		}
+
+		if !reflect.DeepEqual(actual, e.expected) {
+			t.Errorf("Expected %+v to equal %+v", actual, e.expected)
+		}
+	}
+
+	report.Title = "Annual report"
+	if err := acme.Save(report); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := acme.Remove(report); err != nil {
+		t.Fatal(err)
+	}
+
+	count, err := acme.Count(&Document{}, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if count != 0 {
+		t.Errorf("Expected %d to equal 0", count)
+	}
+}
+
+type unscopedDriver struct {
+	driver.Driver
+}
+
+func TestTenantRequiresScopedDriver(t *testing.T) {
+	SetupDriver(unscopedDriver{fake.NewDriver()})
+
+	type Document struct {
+		ModelMetadata `tablename:"documents"`
+
+		ID       int    `rebecca:"id" rebecca_primary:"true"`
+		TenantID int    `rebecca:"tenant_id" rebecca_tenant:"true"`
+		Title    string `rebecca:"title"`
+	}
+
+	acme := (&Context{}).ForTenant(1)
+	report := &Document{Title: "Report"}
+	if err := acme.Save(report); err != nil {
+		t.Fatal(err)
+	}
+
+	report.Title = "Annual report"
+	expected := "Unable to update record &{ModelMetadata:{} ID:1 TenantID:1 Title:Annual report} - driver rebecca.unscopedDriver does not support changing records of tenant-scoped models, it should implement driver.ScopedDriver"
+	if err := acme.Save(report); errRepr(err) != expected {
+		t.Errorf("Expected %s to equal %s", errRepr(err), expected)
+	}
+
+	expected = "Unable to remove record &{ModelMetadata:{} ID:1 TenantID:1 Title:Annual report} - driver rebecca.unscopedDriver does not support changing records of tenant-scoped models, it should implement driver.ScopedDriver"
+	if err := acme.Remove(report); errRepr(err) != expected {
+		t.Errorf("Expected %s to equal %s", errRepr(err), expected)
+	}
+}
+
+type schemaPerTenantDriver struct {
+	*fake.Driver
+
+	tenants map[interface{}]*fake.Driver
+	created map[interface{}][]string
+}
+
+func (d *schemaPerTenantDriver) ForTenant(tenant interface{}) (driver.Driver, error) {
+	tenantDriver, ok := d.tenants[tenant]
+	if !ok {
+		return nil, fmt.Errorf("Unknown tenant %v", tenant)
+	}
+	return tenantDriver, nil
+}
+
+func (d *schemaPerTenantDriver) CreateTenant(tenant interface{}, tablenames []string) error {
+	d.tenants[tenant] = fake.NewDriver()
+	d.created[tenant] = tablenames
+	return nil
+}
+
+func TestTenantDriver(t *testing.T) {
+	d := &schemaPerTenantDriver{
+		Driver:  fake.NewDriver(),
+		tenants: map[interface{}]*fake.Driver{},
+		created: map[interface{}][]string{},
+	}
+	SetupDriver(d)
+
+	type Person struct {
+		ModelMetadata `tablename:"people"`
+
+		ID   int    `rebecca:"id" rebecca_primary:"true"`
+		Name string `rebecca:"name"`
+	}
+
+	for _, tenant := range []string{"acme", "globex"} {
+		if err := CreateTenant(tenant, &Person{}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if !reflect.DeepEqual(d.created["acme"], []string{"people"}) {
+		t.Errorf("Expected %+v to equal %+v", d.created["acme"], []string{"people"})
+	}
+
+	acme := (&Context{}).ForTenant("acme")
+	john := &Person{Name: "John"}
+	if err := acme.Save(john); err != nil {
+		t.Fatal(err)
+	}
+
+	examples := map[string]struct {
+		ctx      *Context
+		expected []Person
+		err      string
+	}{
+		"tenant with records": {
+			ctx:      acme,
+			expected: []Person{*john},
+		},
+
+		"other tenant": {
+			ctx:      (&Context{}).ForTenant("globex"),
+			expected: []Person{},
+		},
+
+		"without tenant": {
+			ctx:      &Context{},
+			expected: []Person{},
+		},
+
+		"unknown tenant": {
+			ctx: (&Context{}).ForTenant("initech"),
+			err: "Unable to route queries to tenant initech - Unknown tenant initech",
+		},
+	}
+
+	for info, e := range examples {
+		t.Log(info)
+		actual := []Person{}
+		err := e.ctx.All(&actual)
+		if errRepr(err) != errRepr(nil) || e.err != "" {
+			if errRepr(err) != e.err {
+				t.Errorf("Expected %s to equal %s", errRepr(err), e.err)
+			}
+			continue
+		}
+
+		if !reflect.DeepEqual(actual, e.expected) {
+			t.Errorf("Expected %+v to equal %+v", actual, e.expected)
+		}
+	}
+
+	SetupDriver(fake.NewDriver())
+	err := CreateTenant("acme", &Person{})
+	expectedErr := "Unable to create tenant acme - driver does not keep records of tenants separately"
+	if errRepr(err) != expectedErr {
+		t.Errorf("Expected %s to equal %s", errRepr(err), expectedErr)
+	}
+}
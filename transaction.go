@@ -92,12 +92,28 @@ func (tx *Transaction) Commit() error {
 
 // Get is for fetching one record
 func (tx *Transaction) Get(record interface{}, ID interface{}) error {
-	return get(tx.tx, record, ID)
+	return get(&Context{tx: tx.tx}, ID, record)
+}
+
+// GetForUpdate is for fetching one record and locking it exclusively until
+// the end of transaction
+func (tx *Transaction) GetForUpdate(record interface{}, ID interface{}) error {
+	meta, err := getMetadata(record)
+	if err != nil {
+		return err
+	}
+
+	if err := ensureHasID(record, meta.primary); err != nil {
+		return err
+	}
+
+	ctx := tx.Context(&Context{Lock: ForUpdate})
+	return ctx.First(record, Eq(meta.primary.DriverName, ID))
 }
 
 // Save is for saving one record (either creating or updating)
 func (tx *Transaction) Save(record interface{}) error {
-	return save(tx.tx, record)
+	return save(&Context{tx: tx.tx}, record)
 }
 
 // All is for fetching all records
@@ -107,20 +123,67 @@ func (tx *Transaction) All(records interface{}) error {
 }
 
 // Where is for fetching specific records
-func (tx *Transaction) Where(records interface{}, where string, args ...interface{}) error {
+func (tx *Transaction) Where(records interface{}, where interface{}, args ...interface{}) error {
 	ctx := tx.Context(&Context{})
 	return ctx.Where(records, where, args...)
 }
 
 // First is for fetching only one specific record
-func (tx *Transaction) First(record interface{}, where string, args ...interface{}) error {
+func (tx *Transaction) First(record interface{}, where interface{}, args ...interface{}) error {
 	ctx := tx.Context(&Context{})
 	return ctx.First(record, where, args...)
 }
 
+// WhereExample is for fetching records, that are equal to the example in all
+// its non-zero fields and in fields listed in include
+func (tx *Transaction) WhereExample(records interface{}, example interface{}, include ...string) error {
+	ctx := tx.Context(&Context{})
+	return ctx.WhereExample(records, example, include...)
+}
+
+// FirstExample is for fetching only first record, that is equal to the
+// example in all its non-zero fields and in fields listed in include
+func (tx *Transaction) FirstExample(record interface{}, example interface{}, include ...string) error {
+	ctx := tx.Context(&Context{})
+	return ctx.FirstExample(record, example, include...)
+}
+
+// Iterate is for fetching specific records lazily one by one
+func (tx *Transaction) Iterate(record interface{}, where interface{}, args ...interface{}) (*Iterator, error) {
+	ctx := tx.Context(&Context{})
+	return ctx.Iterate(record, where, args...)
+}
+
+// Each is for calling fn for each specific record without loading all of
+// them into memory at once
+func (tx *Transaction) Each(record interface{}, where interface{}, args []interface{}, fn interface{}) error {
+	ctx := tx.Context(&Context{})
+	return ctx.Each(record, where, args, fn)
+}
+
+// FindInBatches is for walking through all records in batches of given size,
+// ordered by primary key
+func (tx *Transaction) FindInBatches(records interface{}, size int, fn interface{}) error {
+	ctx := tx.Context(&Context{})
+	return ctx.FindInBatches(records, size, fn)
+}
+
+// Count is for counting specific records
+func (tx *Transaction) Count(record interface{}, where interface{}, args ...interface{}) (int, error) {
+	ctx := tx.Context(&Context{})
+	return ctx.Count(record, where, args...)
+}
+
+// Paginate is for fetching given page of specific records together with the
+// total count of them
+func (tx *Transaction) Paginate(records interface{}, page, perPage int, where interface{}, args ...interface{}) (*Pagination, error) {
+	ctx := tx.Context(&Context{})
+	return ctx.Paginate(records, page, perPage, where, args...)
+}
+
 // Remove is for removing the record
 func (tx *Transaction) Remove(record interface{}) error {
-	return remove(tx.tx, record)
+	return remove(&Context{tx: tx.tx}, record)
 }
 
 // Exec is for executing a query within transaction and discarding its result
@@ -130,11 +193,7 @@ func (tx *Transaction) Exec(query string, args ...interface{}) error {
 
 // Context is for instantiating proper context for transaction
 func (tx *Transaction) Context(ctx *Context) *Context {
-	return &Context{
-		Order: ctx.Order,
-		Group: ctx.Group,
-		Limit: ctx.Limit,
-		Skip:  ctx.Skip,
-		tx:    tx.tx,
-	}
+	txCtx := ctx.makeCopy()
+	txCtx.tx = tx.tx
+	return &txCtx
 }
@@ -203,12 +203,12 @@ func TestTransactions(t *testing.T) {
 	}
 
 	actual := &Post{}
-	if err := txa.Get(pa.ID, actual); err != nil {
+	if err := txa.Get(actual, pa.ID); err != nil {
 		t.Fatal(err)
 	}
 
 	actual = &Post{}
-	if err := txb.Get(pa.ID, actual); err == nil {
+	if err := txb.Get(actual, pa.ID); err == nil {
 		t.Errorf(
 			"Expected transaction B not to find record saved in transaction A, but got: %+v",
 			actual,
@@ -221,7 +221,7 @@ func TestTransactions(t *testing.T) {
 	}
 
 	actual = &Post{}
-	if err := txa.Get(pb.ID, actual); err == nil {
+	if err := txa.Get(actual, pb.ID); err == nil {
 		t.Errorf(
 			"Expected transaction A not to find record saved in transaction B, but got: %+v",
 			actual,
@@ -290,7 +290,7 @@ func TestTransactions(t *testing.T) {
 	}
 
 	actual = &Post{}
-	if err := txc.Get(pa.ID, actual); err != nil {
+	if err := txc.Get(actual, pa.ID); err != nil {
 		t.Fatal(err)
 	}
 
@@ -396,6 +396,49 @@ func TestDoubleCommit(t *testing.T) {
 	}
 }
 
+func TestTransactionGet(t *testing.T) {
+	rebecca.SetupDriver(fake.NewDriver())
+
+	type Article struct {
+		rebecca.ModelMetadata `tablename:"articles"`
+
+		ID        int    `rebecca:"id" rebecca_primary:"true"`
+		Title     string `rebecca:"title"`
+		Published bool   `rebecca:"published"`
+	}
+
+	if err := rebecca.DefineDefaultScope(&Article{}, rebecca.Eq("published", true)); err != nil {
+		t.Fatal(err)
+	}
+
+	published := &Article{Title: "Hello", Published: true}
+	draft := &Article{Title: "Draft"}
+	for _, a := range []*Article{published, draft} {
+		if err := rebecca.Save(a); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tx, err := rebecca.Begin()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer tx.Rollback()
+
+	actual := &Article{}
+	if err := tx.Get(actual, published.ID); err != nil {
+		t.Fatal(err)
+	}
+
+	if *actual != *published {
+		t.Errorf("Expected %+v to equal %+v", actual, published)
+	}
+
+	if err := tx.Get(&Article{}, draft.ID); err == nil {
+		t.Errorf("Expected default scope to hide record %+v", draft)
+	}
+}
+
 func equalPosts(l, r []Post) bool {
 	if len(l) != len(r) {
 		return false
@@ -0,0 +1,167 @@
+package rebecca
+
+// This file contains thin exported functions and types of the type-safe API
+// only. It is built on top of Context.
+//
+// For Context see: context.go
+
+import "github.com/waterlink/rebecca/context"
+
+// Find is for fetching one record of type T by its primary key, for example:
+//
+//	person, err := rebecca.Find[Person](ctx, 25)
+//
+// ctx is optional and can be nil. T is any model, either the one embedding
+// ModelMetadata or registered with RegisterModel. Go constraints can not
+// tell registered models from other types, so T is not checked at compile
+// time, and types, that are not models, fail with an error at run time
+func Find[T any](ctx *Context, ID interface{}) (*T, error) {
+	record := new(T)
+	if err := get(contextOrDefault(ctx), ID, record); err != nil {
+		return nil, err
+	}
+	return record, nil
+}
+
+// TypedQuery is for building the query of records of type T, see Query. It
+// is immutable, each method creates new TypedQuery. T is checked at run
+// time, the same as for Find
+type TypedQuery[T any] struct {
+	ctx   *Context
+	where interface{}
+	args  []interface{}
+}
+
+// Query is for building the query of records of type T, for example:
+//
+//	people, err := rebecca.Query[Person]().Where(rebecca.Gt("age", 18)).Order("name ASC").All()
+func Query[T any]() *TypedQuery[T] {
+	return &TypedQuery[T]{ctx: &Context{}, where: ""}
+}
+
+// Context is for running the query with given Context, for example, the one
+// of transaction
+func (q *TypedQuery[T]) Context(ctx *Context) *TypedQuery[T] {
+	query := *q
+	query.ctx = contextOrDefault(ctx)
+	return &query
+}
+
+// Where is for filtering records, where is either a query with placeholders
+// for args or a structured condition. It replaces previous where query
+func (q *TypedQuery[T]) Where(where interface{}, args ...interface{}) *TypedQuery[T] {
+	query := *q
+	query.where = where
+	query.args = args
+	return &query
+}
+
+// Order is for ordering records, see Context.Order
+func (q *TypedQuery[T]) Order(order string) *TypedQuery[T] {
+	query := *q
+	ctx := q.ctx.makeCopy()
+	ctx.Order = order
+	query.ctx = &ctx
+	return &query
+}
+
+// OrderBy is for ordering records by validated columns, see Context.OrderBy
+func (q *TypedQuery[T]) OrderBy(orderings ...context.Ordering) *TypedQuery[T] {
+	query := *q
+	query.ctx = q.ctx.OrderBy(orderings...)
+	return &query
+}
+
+// Limit is for limiting amount of records
+func (q *TypedQuery[T]) Limit(limit int) *TypedQuery[T] {
+	query := *q
+	ctx := q.ctx.makeCopy()
+	ctx.Limit = limit
+	query.ctx = &ctx
+	return &query
+}
+
+// Skip is for skipping first records
+func (q *TypedQuery[T]) Skip(skip int) *TypedQuery[T] {
+	query := *q
+	ctx := q.ctx.makeCopy()
+	ctx.Skip = skip
+	ctx.Offset = 0
+	query.ctx = &ctx
+	return &query
+}
+
+// All is for fetching all records matching the query
+func (q *TypedQuery[T]) All() ([]T, error) {
+	records := []T{}
+	if err := q.ctx.Where(&records, q.where, q.args...); err != nil {
+		return nil, err
+	}
+	return records, nil
+}
+
+// First is for fetching only first record matching the query
+func (q *TypedQuery[T]) First() (*T, error) {
+	record := new(T)
+	if err := q.ctx.First(record, q.where, q.args...); err != nil {
+		return nil, err
+	}
+	return record, nil
+}
+
+// Count is for counting records matching the query
+func (q *TypedQuery[T]) Count() (int, error) {
+	return q.ctx.Count(new(T), q.where, q.args...)
+}
+
+// Repository is for working with records of type T, its zero value is ready
+// to use, for example:
+//
+//	people := rebecca.Repository[Person]{}
+//	person, err := people.Get(25)
+//
+// Context is optional, set it, for example, to work within transaction. T is
+// checked at run time, the same as for Find
+type Repository[T any] struct {
+	Context *Context
+}
+
+// Get is for fetching one record by its primary key
+func (r Repository[T]) Get(ID interface{}) (*T, error) {
+	return Find[T](r.Context, ID)
+}
+
+// All is for fetching all records
+func (r Repository[T]) All() ([]T, error) {
+	return r.Query().All()
+}
+
+// Where is for fetching specific records
+func (r Repository[T]) Where(where interface{}, args ...interface{}) ([]T, error) {
+	return r.Query().Where(where, args...).All()
+}
+
+// First is for fetching only first specific record
+func (r Repository[T]) First(where interface{}, args ...interface{}) (*T, error) {
+	return r.Query().Where(where, args...).First()
+}
+
+// Count is for counting specific records
+func (r Repository[T]) Count(where interface{}, args ...interface{}) (int, error) {
+	return r.Query().Where(where, args...).Count()
+}
+
+// Query is for building the query of records, see Query
+func (r Repository[T]) Query() *TypedQuery[T] {
+	return Query[T]().Context(r.Context)
+}
+
+// Save is for saving one record (either creating or updating)
+func (r Repository[T]) Save(record *T) error {
+	return save(contextOrDefault(r.Context), record)
+}
+
+// Remove is for removing the record
+func (r Repository[T]) Remove(record *T) error {
+	return remove(contextOrDefault(r.Context), record)
+}
@@ -0,0 +1,161 @@
+package rebecca
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/waterlink/rebecca/driver/fake"
+)
+
+type typedPerson struct {
+	ModelMetadata `tablename:"people"`
+
+	ID   int    `rebecca:"id" rebecca_primary:"true"`
+	Name string `rebecca:"name"`
+	Age  int    `rebecca:"age"`
+}
+
+func TestTypedAPI(t *testing.T) {
+	SetupDriver(fake.NewDriver())
+
+	people := Repository[typedPerson]{}
+	john := &typedPerson{Name: "John", Age: 34}
+	jane := &typedPerson{Name: "Jane", Age: 17}
+	bob := &typedPerson{Name: "Bob", Age: 45}
+	for _, p := range []*typedPerson{john, jane, bob} {
+		if err := people.Save(p); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	found, err := Find[typedPerson](nil, jane.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(found, jane) {
+		t.Errorf("Expected %+v to equal %+v", found, jane)
+	}
+
+	adults := Query[typedPerson]().Where(Gt("age", 18))
+	examples := map[string]struct {
+		action   func() (interface{}, error)
+		expected interface{}
+	}{
+		"Query All": {
+			action:   func() (interface{}, error) { return adults.OrderBy(Desc("age")).All() },
+			expected: []typedPerson{*bob, *john},
+		},
+
+		"Query First": {
+			action:   func() (interface{}, error) { return adults.Order("age").First() },
+			expected: john,
+		},
+
+		"Query Count": {
+			action:   func() (interface{}, error) { return adults.Count() },
+			expected: 2,
+		},
+
+		"Query is immutable": {
+			action:   func() (interface{}, error) { return adults.All() },
+			expected: []typedPerson{*john, *bob},
+		},
+
+		"Query with limit and skip": {
+			action:   func() (interface{}, error) { return Query[typedPerson]().OrderBy(Asc("age")).Skip(1).Limit(1).All() },
+			expected: []typedPerson{*john},
+		},
+
+		"Repository Get": {
+			action:   func() (interface{}, error) { return people.Get(bob.ID) },
+			expected: bob,
+		},
+
+		"Repository All": {
+			action:   func() (interface{}, error) { return people.All() },
+			expected: []typedPerson{*john, *jane, *bob},
+		},
+
+		"Repository Where": {
+			action:   func() (interface{}, error) { return people.Where(Lt("age", 18)) },
+			expected: []typedPerson{*jane},
+		},
+
+		"Repository First": {
+			action:   func() (interface{}, error) { return people.First(Eq("name", "Bob")) },
+			expected: bob,
+		},
+
+		"Repository Count": {
+			action:   func() (interface{}, error) { return people.Count(Gt("age", 40)) },
+			expected: 1,
+		},
+	}
+
+	for info, e := range examples {
+		t.Log(info)
+		actual, err := e.action()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if !reflect.DeepEqual(actual, e.expected) {
+			t.Errorf("Expected %+v to equal %+v", actual, e.expected)
+		}
+	}
+
+	if err := people.Remove(jane); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := people.Get(jane.ID); err == nil {
+		t.Errorf("Expected record to be removed")
+	}
+}
+
+func TestTypedAPIWithRegisteredModel(t *testing.T) {
+	SetupDriver(fake.NewDriver())
+
+	type registeredPerson struct {
+		ID   int
+		Name string
+	}
+
+	if err := RegisterModel(&registeredPerson{}, Table("people"), Column("ID", "id", Primary()), Column("Name", "name")); err != nil {
+		t.Fatal(err)
+	}
+
+	people := Repository[registeredPerson]{}
+	john := &registeredPerson{Name: "John"}
+	if err := people.Save(john); err != nil {
+		t.Fatal(err)
+	}
+
+	found, err := Find[registeredPerson](nil, john.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(found, john) {
+		t.Errorf("Expected %+v to equal %+v", found, john)
+	}
+
+	all, err := people.Where(Eq("name", "John"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(all, []registeredPerson{*john}) {
+		t.Errorf("Expected %+v to equal %+v", all, []registeredPerson{*john})
+	}
+
+	type unknownPerson struct {
+		ID int
+	}
+
+	if _, err := Find[unknownPerson](nil, 1); err == nil {
+		t.Errorf("Expected error for model without metadata")
+	}
+
+	if _, err := Query[int]().All(); err == nil {
+		t.Errorf("Expected error for type, that is not a model")
+	}
+}